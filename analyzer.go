@@ -6,7 +6,9 @@ import (
 	"errors"
 	"io"
 	"regexp"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/kmcsr/go-ringbuf"
@@ -15,12 +17,33 @@ import (
 type SolutionPossibility struct {
 	ErrorDesc *ErrorDesc `json:"errorDesc"`
 	Match     float32    `json:"match"`
+	// Data is the captured groups of ErrorDesc.Pattern
+	Data map[string]any `json:"data,omitempty"`
+}
+
+// TemplateData returns the values that can be used by the solution placeholders
+func (p *SolutionPossibility) TemplateData() (data map[string]any) {
+	if len(p.Data) == 0 {
+		return p.ErrorDesc.Data
+	}
+	data = make(map[string]any, len(p.ErrorDesc.Data)+len(p.Data))
+	for k, v := range p.ErrorDesc.Data {
+		data[k] = v
+	}
+	for k, v := range p.Data {
+		data[k] = v
+	}
+	return
 }
 
 type ErrorResult struct {
 	Error   *JavaError            `json:"error"`
 	Matched []SolutionPossibility `json:"matched"`
-	File    string                `json:"file,omitempty"`
+	// Solutions are filled only when Analyzer.ResolveSolutions is true
+	Solutions []*ResolvedSolution `json:"solutions,omitempty"`
+	// UnresolvedSolutions are the solutions failed to fetch, the other solutions are still resolved
+	UnresolvedSolutions []UnresolvedSolution `json:"unresolvedSolutions,omitempty"`
+	File                string               `json:"file,omitempty"`
 }
 
 var (
@@ -30,17 +53,32 @@ var (
 type Analyzer struct {
 	DB ErrorDB
 
+	// ResolveSolutions makes DoLogStream embed the matched solutions in the results
+	ResolveSolutions bool
+	// Locale of the resolved solutions
+	Locale string
+	// SolutionTags filters the resolved solutions, a solution is kept if it has any of the tags.
+	// No solution is filtered out if it's empty
+	SolutionTags []string
+	// MinSolutionMatch is the minimum match of an error to have its solutions resolved
+	MinSolutionMatch float32
+	// Encoding of the files analyzed by AnalyzeFile, it's detected if empty or "auto"
+	Encoding string
+
 	errMux        sync.RWMutex
 	lastUpdateErr time.Time
 	cachedErrors  []*ErrorDesc
 
-	recentMixinLogs *ringbuf.RingBuffer[string]
+	solMux          sync.RWMutex
+	cachedSolutions map[int]cachedSolution
+
+	// latestRecorder is the recorder of the latest log stream, it's used by DoError and HardCodedChecks
+	latestRecorder atomic.Pointer[logRecorder]
 }
 
 func NewAnalyzer(db ErrorDB) (a *Analyzer) {
 	return &Analyzer{
-		DB:              db,
-		recentMixinLogs: ringbuf.NewRingBuffer[string](64),
+		DB: db,
 	}
 }
 
@@ -77,8 +115,14 @@ func (a *Analyzer) getErrors() []*ErrorDesc {
 	return a.cachedErrors
 }
 
+// DoError matches the error, the mixin logs of the latest log stream of DoLogStream are used by the hard-coded checks
 func (a *Analyzer) DoError(jerr *JavaError) (matched []SolutionPossibility, err error) {
-	e, _ := a.HardCodedChecks(jerr)
+	return a.doError(jerr, a.latestRecorder.Load())
+}
+
+// doError matches the error, the recorder provides the log context of the stream and can be nil
+func (a *Analyzer) doError(jerr *JavaError, recorder *logRecorder) (matched []SolutionPossibility, err error) {
+	e, _ := a.hardCodedChecks(jerr, recorder)
 	if e != nil {
 		return []SolutionPossibility{
 			SolutionPossibility{
@@ -101,11 +145,23 @@ func (a *Analyzer) DoError(jerr *JavaError) (matched []SolutionPossibility, err
 				sol.Match = 0.05 // 5%
 			}
 		}
-		if len(e.Message) == 0 { // when ignore error message, error type provide 100% score weight
+		if len(e.Message) == 0 && len(e.Pattern) == 0 { // when ignore error message, error type provide 100% score weight
 			sol.Match /= 10.0 / 100
 		} else {
-			jemsg, _ := split(jerr.Message, '\n')
-			matches := lineMatchPercent(jemsg, e.Message) // error message weight: 90%
+			var matches float32 // error message weight: 90%
+			if len(e.Pattern) != 0 {
+				re, err := e.CompilePattern()
+				if err != nil { // invalid entry
+					continue
+				}
+				if groups := re.FindStringSubmatch(jerr.Message); groups != nil {
+					matches = 1
+					sol.Data = capturedData(re, groups)
+				}
+			} else {
+				jemsg, _ := split(jerr.Message, '\n')
+				matches = lineMatchPercent(jemsg, e.Message)
+			}
 			if ignoreErrorTyp {
 				sol.Match = matches // or when ignore error type, it provide 100% score weight
 			} else {
@@ -122,15 +178,83 @@ func (a *Analyzer) DoError(jerr *JavaError) (matched []SolutionPossibility, err
 	return
 }
 
+type scanWarningsKey struct{}
+
+type scanWarnings struct {
+	mux  sync.Mutex
+	list []ScanWarning
+}
+
+func (w *scanWarnings) add(warning ScanWarning) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	w.list = append(w.list, warning)
+}
+
+// LogWarnings returns the warnings of the log stream, like the truncated long lines and the skipped binary data.
+// The context must be the one returned by DoLogStream, and the warnings are complete once the stream is done
+func LogWarnings(ctx context.Context) []ScanWarning {
+	w, ok := ctx.Value(scanWarningsKey{}).(*scanWarnings)
+	if !ok {
+		return nil
+	}
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	return slices.Clone(w.list)
+}
+
+// DoLogStream scans the errors in the log and matches them concurrently, the lines longer than MaxLineSize
+// are truncated and the binary data is skipped, see LogWarnings.
+// The crash reports printed in the log are analyzed like AnalyzeCrashReport, their errors are sent
+// with the line numbers in the log once the whole report is read, see LogCrashReports
 func (a *Analyzer) DoLogStream(c context.Context, r io.Reader) (<-chan *ErrorResult, context.Context) {
 	result := make(chan *ErrorResult, 3)
 	ctx, cancel := context.WithCancelCause(c)
+	warnings := new(scanWarnings)
+	ctx = context.WithValue(ctx, scanWarningsKey{}, warnings)
+	reports := new(logReports)
+	ctx = context.WithValue(ctx, logReportsKey{}, reports)
 	go func() {
 		defer close(result)
 		var wg sync.WaitGroup
 		recorder := a.newLogRecorder()
 		defer recorder.Close()
-		resCh, errCh := ScanJavaErrorsIntoChan(io.TeeReader(r, recorder))
+		send := func(res *ErrorResult) bool {
+			select {
+			case result <- res:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		filter := &crashReportFilter{
+			sc: newLineScanner(io.TeeReader(decodeReader(r), recorder)),
+			// called by the scanning goroutine before resCh is closed, so wg.Wait is not called yet
+			onReport: func(report *EmbeddedCrashReport) {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					report.toLogLineNo()
+					cr, err := a.analyzeCrashReport(report.CrashReport, recorder)
+					if err != nil {
+						cancel(err)
+						return
+					}
+					reports.add(&EmbeddedReportResult{
+						CrashReportResult: cr,
+						LineNo:            report.LineNo,
+						SavedTo:           report.SavedTo,
+					})
+					for _, res := range cr.Errors {
+						if !send(res) {
+							return
+						}
+					}
+				}()
+			},
+		}
+		filter.sc.onWarning = warnings.add
+		resCh, errCh := scanJavaErrorsIntoChan(filter, nil)
 	LOOP:
 		for {
 			select {
@@ -146,13 +270,14 @@ func (a *Analyzer) DoLogStream(c context.Context, r io.Reader) (<-chan *ErrorRes
 							Error: jerr,
 						}
 						var err error
-						if res.Matched, err = a.DoError(jerr); err != nil {
+						if res.Matched, err = a.doError(jerr, recorder); err != nil {
 							cancel(err)
 							return
 						}
-						select {
-						case result <- res:
-						case <-ctx.Done():
+						if a.ResolveSolutions {
+							a.ResolveResult(res)
+						}
+						if !send(res) {
 							return
 						}
 						jerr = jerr.CausedBy
@@ -170,20 +295,32 @@ func (a *Analyzer) DoLogStream(c context.Context, r io.Reader) (<-chan *ErrorRes
 	return result, ctx
 }
 
+// logRecorder keeps the recent lines of one log stream,
+// so the streams analyzed concurrently by the same Analyzer will not affect each other
 type logRecorder struct {
-	a      *Analyzer
-	closed bool
-	buf    []byte
+	// mux guards all the fields, the scanning may still write when the stream is cancelled and closed
+	mux      sync.Mutex
+	closed   bool
+	buf      []byte
+	skipping bool // the rest of the current line is dropped
+
+	recentMixinLogs *ringbuf.RingBuffer[string]
 }
 
-func (a *Analyzer) newLogRecorder() io.WriteCloser {
-	a.recentMixinLogs.Clear()
-	return &logRecorder{
-		a: a,
+func (a *Analyzer) newLogRecorder() (r *logRecorder) {
+	r = &logRecorder{
+		recentMixinLogs: ringbuf.NewRingBuffer[string](64),
 	}
+	a.latestRecorder.Store(r)
+	return
 }
 
 func (r *logRecorder) Write(buf []byte) (int, error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.closed {
+		return len(buf), nil
+	}
 	r.buf = append(r.buf, buf...)
 	i := 0
 	for  {
@@ -191,17 +328,28 @@ func (r *logRecorder) Write(buf []byte) (int, error) {
 		if j < i {
 			break
 		}
-		r.record(r.buf[i:j])
+		if r.skipping {
+			r.skipping = false
+		} else {
+			r.record(r.buf[i:j])
+		}
 		i = j + 1
 	}
 	if i > 0 {
 		n := copy(r.buf, r.buf[i:])
 		r.buf = r.buf[:n]
 	}
+	// a line longer than MaxLineSize is never a mixin log, it's dropped to keep the memory bounded
+	if len(r.buf) > MaxLineSize {
+		r.buf = r.buf[:0]
+		r.skipping = true
+	}
 	return len(buf), nil
 }
 
 func (r *logRecorder) Close() error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
 	if r.closed {
 		return nil
 	}
@@ -214,9 +362,27 @@ var (
 	mixinLogRe = regexp.MustCompile(`^\[[^\]]*\]\s*\[[^\]]*\]\s*\[mixin/[^\]]*\]:\s*(.+)$`)
 )
 
+// record must be called with r.mux held
 func (r *logRecorder) record(buf []byte) {
+	if bytes.IndexByte(buf, '\x1b') >= 0 {
+		buf = ansiEscapeRe.ReplaceAll(buf, nil)
+	}
 	matches := mixinLogRe.FindSubmatch(buf)
 	if matches != nil {
-		r.a.recentMixinLogs.Push((string)(matches[1]))
+		r.recentMixinLogs.Push((string)(matches[1]))
 	}
 }
+
+// mixinLogs returns the recorded mixin logs from the newest to the oldest
+func (r *logRecorder) mixinLogs() (lines []string) {
+	if r == nil {
+		return nil
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	lines = make([]string, 0, r.recentMixinLogs.Len())
+	for line := range r.recentMixinLogs.IterReversed() {
+		lines = append(lines, line)
+	}
+	return
+}
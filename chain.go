@@ -0,0 +1,120 @@
+package mcla
+
+import (
+	"slices"
+)
+
+// ChainResult is the results of a logged error and its causes, the root error comes first.
+// DoLogStream sends a result for every error in the cause chain, and they can be grouped back with it
+type ChainResult []*ErrorResult
+
+func (c ChainResult) Root() *ErrorResult {
+	return c[0]
+}
+
+func chainLength(jerr *JavaError) (n int) {
+	for ; jerr != nil; jerr = jerr.CausedBy {
+		n++
+	}
+	return
+}
+
+// Complete reports whether the results of all causes are present
+func (c ChainResult) Complete() bool {
+	return len(c) > 0 && len(c) == chainLength(c[0].Error)
+}
+
+// Solutions merges the resolved solutions of the chain,
+// a solution appears once with its best match, sorted by the match in descending order
+func (c ChainResult) Solutions() (solutions []*ResolvedSolution) {
+	index := make(map[int]int)
+	for _, res := range c {
+		for _, sol := range res.Solutions {
+			if i, ok := index[sol.ID]; ok {
+				if solutions[i].Match < sol.Match {
+					solutions[i] = sol
+				}
+				continue
+			}
+			index[sol.ID] = len(solutions)
+			solutions = append(solutions, sol)
+		}
+	}
+	slices.SortStableFunc(solutions, func(x, y *ResolvedSolution) int {
+		switch {
+		case x.Match > y.Match:
+			return -1
+		case x.Match < y.Match:
+			return 1
+		}
+		return 0
+	})
+	return
+}
+
+// ChainCollector groups the results from a stream, the results of a chain
+// must be added in the order of the chain, which DoLogStream does
+type ChainCollector struct {
+	roots   map[*JavaError]*ErrorResult // cause -> root result
+	pending map[*ErrorResult]ChainResult
+}
+
+func NewChainCollector() *ChainCollector {
+	return &ChainCollector{
+		roots:   make(map[*JavaError]*ErrorResult),
+		pending: make(map[*ErrorResult]ChainResult),
+	}
+}
+
+// Add returns the chain once all of its results are added
+func (c *ChainCollector) Add(res *ErrorResult) (chain ChainResult, ok bool) {
+	root, isCause := c.roots[res.Error]
+	if !isCause {
+		root = res
+		for cause := res.Error.CausedBy; cause != nil; cause = cause.CausedBy {
+			c.roots[cause] = root
+		}
+	}
+	chain = append(c.pending[root], res)
+	if !chain.Complete() {
+		c.pending[root] = chain
+		return nil, false
+	}
+	delete(c.pending, root)
+	for cause := root.Error.CausedBy; cause != nil; cause = cause.CausedBy {
+		delete(c.roots, cause)
+	}
+	return chain, true
+}
+
+// Flush returns the incomplete chains, e.g. when the stream was interrupted
+func (c *ChainCollector) Flush() (chains []ChainResult) {
+	for _, chain := range c.pending {
+		chains = append(chains, chain)
+	}
+	clear(c.pending)
+	clear(c.roots)
+	sortChainResults(chains)
+	return
+}
+
+// GroupChainResults groups the results into chains sorted by the line numbers of their root errors
+func GroupChainResults(results []*ErrorResult) (chains []ChainResult) {
+	sorted := slices.Clone(results)
+	sortErrorResults(sorted)
+	c := NewChainCollector()
+	for _, res := range sorted {
+		if chain, ok := c.Add(res); ok {
+			chains = append(chains, chain)
+		}
+	}
+	chains = append(chains, c.Flush()...)
+	sortChainResults(chains)
+	return
+}
+
+func sortChainResults(chains []ChainResult) {
+	slices.SortStableFunc(chains, func(a, b ChainResult) int {
+		return a.Root().Error.LineNo - b.Root().Error.LineNo
+	})
+}
@@ -0,0 +1,168 @@
+package mcla
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// maxClusterFrames is how many frames of each error are compared, the frames deep in the
+// stacktrace are usually the same game loop and tell nothing about the error
+const maxClusterFrames = 32
+
+func frameKeys(st Stacktrace) []string {
+	keys := make([]string, 0, min(len(st), maxClusterFrames))
+	for _, s := range st[:min(len(st), maxClusterFrames)] {
+		keys = append(keys, s.Class+"."+s.Method)
+	}
+	return keys
+}
+
+// similarPercent is lcsPercent, but two empty lists are the same
+func similarPercent[T comparable](a, b []T) float32 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	return lcsPercent(a, b)
+}
+
+// ErrorSimilarity scores how similar two errors are, from 0 to 1.
+// The classes of the cause chains, the normalized messages and the frames of the root causes are compared,
+// so the errors with different coordinates, entity IDs or object hashes in the messages are still similar
+func ErrorSimilarity(a, b *JavaError) float32 {
+	var (
+		classesA, classesB   []string
+		messagesA, messagesB []string
+	)
+	for e := a; e != nil; e = e.CausedBy {
+		classesA = append(classesA, e.Class)
+		messagesA = append(messagesA, NormalizeMessage(e.Message))
+	}
+	for e := b; e != nil; e = e.CausedBy {
+		classesB = append(classesB, e.Class)
+		messagesB = append(messagesB, NormalizeMessage(e.Message))
+	}
+	classMatch := similarPercent(classesA, classesB)
+	if classMatch == 0 {
+		return 0
+	}
+	msgMatch := similarPercent(([]rune)(strings.Join(messagesA, "\n")), ([]rune)(strings.Join(messagesB, "\n")))
+	stackMatch := similarPercent(frameKeys(a.RootCause().Stacktrace), frameKeys(b.RootCause().Stacktrace))
+	return classMatch*0.3 + msgMatch*0.3 + stackMatch*0.4
+}
+
+type ClusterMember struct {
+	File   string     `json:"file"`
+	LineNo int        `json:"lineNo"`
+	Error  *JavaError `json:"-"`
+	// Similarity is the similarity to the representative of the cluster
+	Similarity float32 `json:"similarity"`
+}
+
+// ErrorCluster is a group of similar errors, the first added error is the representative
+type ErrorCluster struct {
+	ID             int              `json:"id"`
+	Fingerprint    string           `json:"fingerprint"`
+	Representative *JavaError       `json:"representative"`
+	Members        []*ClusterMember `json:"members"`
+	// Files are the distinct files of the members in the order they are added
+	Files []string `json:"files"`
+	// Solutions are the IDs of the matched solutions of all members
+	Solutions []int `json:"solutions"`
+}
+
+// Known reports whether any member matched a solution,
+// the unknown clusters are the candidates of new database entries
+func (c *ErrorCluster) Known() bool {
+	return len(c.Solutions) > 0
+}
+
+const DefaultClusterThreshold = 0.8
+
+// Clusterer groups the similar errors into clusters, it's safe for concurrent use.
+// An error joins the most similar cluster if the similarity reaches the threshold,
+// otherwise it starts a new cluster, so the clusters depend on the order of the errors
+type Clusterer struct {
+	Threshold float32
+
+	mux           sync.Mutex
+	clusters      []*ErrorCluster
+	byFingerprint map[string]clusterRef
+}
+
+// clusterRef caches the cluster of a fingerprint, since the errors with the same fingerprint
+// have the same similarity to the representative
+type clusterRef struct {
+	cluster    *ErrorCluster
+	similarity float32
+}
+
+func NewClusterer(threshold float32) *Clusterer {
+	return &Clusterer{
+		Threshold:     threshold,
+		byFingerprint: make(map[string]clusterRef),
+	}
+}
+
+// Add adds an error and the IDs of its matched solutions, and returns the cluster it joined
+func (c *Clusterer) Add(file string, jerr *JavaError, solutions []int) *ErrorCluster {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	fp := Fingerprint(jerr)
+	ref, ok := c.byFingerprint[fp]
+	if !ok {
+		for _, cl := range c.clusters {
+			if v := ErrorSimilarity(cl.Representative, jerr); v > ref.similarity {
+				ref = clusterRef{cl, v}
+			}
+		}
+		if ref.cluster == nil || ref.similarity < c.Threshold {
+			ref.cluster = &ErrorCluster{
+				ID:             len(c.clusters) + 1,
+				Fingerprint:    fp,
+				Representative: jerr,
+			}
+			ref.similarity = 1
+			c.clusters = append(c.clusters, ref.cluster)
+		}
+		c.byFingerprint[fp] = ref
+	}
+	cluster := ref.cluster
+	cluster.Members = append(cluster.Members, &ClusterMember{
+		File:       file,
+		LineNo:     jerr.LineNo,
+		Error:      jerr,
+		Similarity: ref.similarity,
+	})
+	cluster.Files = addUnique(cluster.Files, file)
+	for _, id := range solutions {
+		if !slices.Contains(cluster.Solutions, id) {
+			cluster.Solutions = append(cluster.Solutions, id)
+		}
+	}
+	return cluster
+}
+
+// AddResult adds the root errors of the result's cause chains
+func (c *Clusterer) AddResult(res *InstanceFileResult) {
+	for _, chain := range GroupChainResults(res.Errors) {
+		var ids []int
+		for _, sol := range chain.Solutions() {
+			ids = append(ids, sol.ID)
+		}
+		c.Add(res.Name, chain.Root().Error, ids)
+	}
+}
+
+// Clusters returns the clusters sorted by their sizes in descending order
+func (c *Clusterer) Clusters() []*ErrorCluster {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	clusters := slices.Clone(c.clusters)
+	slices.SortStableFunc(clusters, func(a, b *ErrorCluster) int {
+		return len(b.Members) - len(a.Members)
+	})
+	return clusters
+}
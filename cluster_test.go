@@ -0,0 +1,61 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+)
+
+func tickError(msg string, frames ...string) *JavaError {
+	st := make(Stacktrace, len(frames))
+	for i, f := range frames {
+		st[i] = StackInfo{Class: f, Method: "tick"}
+	}
+	return &JavaError{
+		Class:   "net.minecraft.ReportedException",
+		Message: "Ticking entity",
+		CausedBy: &JavaError{
+			Class:      "java.lang.NullPointerException",
+			Message:    msg,
+			Stacktrace: st,
+		},
+	}
+}
+
+func TestErrorSimilarity(t *testing.T) {
+	a := tickError("Entity 1234 at (10, 64, -20) is null", "com.example.mod.Mob", "net.minecraft.world.entity.Entity", "net.minecraft.server.level.ServerLevel")
+	b := tickError("Entity 5678 at (-300, 12, 7) is null", "com.example.mod.Mob", "net.minecraft.world.entity.Entity", "net.minecraft.server.level.ServerLevel")
+	c := &JavaError{Class: "java.lang.OutOfMemoryError", Message: "Java heap space"}
+	if v := ErrorSimilarity(a, a); v != 1 {
+		t.Errorf("Expect an error is the same as itself, got %v", v)
+	}
+	if v := ErrorSimilarity(a, b); v < DefaultClusterThreshold {
+		t.Errorf("Expect errors with different numbers are similar, got %v", v)
+	}
+	if v := ErrorSimilarity(a, c); v != 0 {
+		t.Errorf("Expect errors with different classes are not similar, got %v", v)
+	}
+}
+
+func TestClusterer(t *testing.T) {
+	c := NewClusterer(DefaultClusterThreshold)
+	frames := []string{"com.example.mod.Mob", "net.minecraft.world.entity.Entity", "net.minecraft.server.level.ServerLevel"}
+	c.Add("a.log", tickError("Entity 1234 is null", frames...), nil)
+	c.Add("b.log", &JavaError{Class: "java.lang.OutOfMemoryError", Message: "Java heap space"}, []int{2})
+	c.Add("b.log", tickError("Entity 5678 is null", frames...), []int{1})
+	c.Add("c.log", tickError("Entity 42 is null", append([]string{"com.example.mod.Brain"}, frames...)...), nil)
+
+	clusters := c.Clusters()
+	if len(clusters) != 2 {
+		t.Fatalf("Expect 2 clusters, got %d", len(clusters))
+	}
+	tick := clusters[0]
+	if tick.ID != 1 || len(tick.Members) != 3 || len(tick.Files) != 3 {
+		t.Errorf("Unexpected cluster %d with %d members in %v", tick.ID, len(tick.Members), tick.Files)
+	}
+	if !tick.Known() || len(tick.Solutions) != 1 || tick.Solutions[0] != 1 {
+		t.Errorf("Expect solution 1 in the cluster, got %v", tick.Solutions)
+	}
+	if m := tick.Members[2]; m.Similarity >= 1 || m.Similarity < DefaultClusterThreshold {
+		t.Errorf("Unexpected similarity %v of the member with an extra frame", m.Similarity)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/GlobeMC/mcla"
+)
+
+// clusterCommand groups the similar errors of all crash reports and logs in a directory,
+// the unknown clusters are worth writing new database entries for
+func clusterCommand(args []string) {
+	flags := flag.NewFlagSet("cluster", flag.ExitOnError)
+	threshold := flags.Float64("threshold", mcla.DefaultClusterThreshold, "")
+	unknown := flags.Bool("unknown", false, "")
+	examples := flags.Int("examples", 3, "")
+	workers := flags.Int("j", 0, "")
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		printf("[ERROR]: Must give the directory as the argument")
+		os.Exit(exitUsage)
+	}
+	dir := flags.Arg(0)
+	files, err := mcla.FindBatchFiles(os.DirFS(dir))
+	if err != nil {
+		printf("Error when reading directory %q: %v", dir, err)
+		os.Exit(exitFailure)
+	}
+	if len(files) == 0 {
+		printf("No any log was found in %q", dir)
+		return
+	}
+
+	var (
+		mux     sync.Mutex
+		results []*mcla.InstanceFileResult
+	)
+	analyzeFiles(files, *workers, func(res *mcla.InstanceFileResult) {
+		if res.Failure != "" {
+			printf("[WARN]: Cannot analyze %q: %s", res.Name, res.Failure)
+		}
+		mux.Lock()
+		results = append(results, res)
+		mux.Unlock()
+	})
+	// the clusters depend on the order of the errors, so the files are added by their names
+	slices.SortFunc(results, func(a, b *mcla.InstanceFileResult) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	clusterer := mcla.NewClusterer((float32)(*threshold))
+	for _, res := range results {
+		clusterer.AddResult(res)
+	}
+	clusters := clusterer.Clusters()
+	if *unknown {
+		clusters = slices.DeleteFunc(clusters, (*mcla.ErrorCluster).Known)
+	}
+
+	switch output {
+	case outputJSON, outputNDJSON:
+		err = newJSONEncoder().Encode(clusters)
+	default:
+		err = writeClustersText(os.Stdout, clusters, *examples)
+	}
+	if err != nil {
+		printf("Error when printing clusters: %v", err)
+		os.Exit(exitFailure)
+	}
+}
+
+func writeClustersText(w io.Writer, clusters []*mcla.ErrorCluster, examples int) (err error) {
+	for _, c := range clusters {
+		known := "unknown"
+		if c.Known() {
+			ids := make([]string, len(c.Solutions))
+			for i, id := range c.Solutions {
+				ids[i] = "#" + strconv.Itoa(id)
+			}
+			known = "solutions " + strings.Join(ids, ", ")
+		}
+		fmt.Fprintf(w, "Cluster %d: %d error(s) in %d file(s), %s\n", c.ID, len(c.Members), len(c.Files), known)
+		for e := c.Representative; e != nil; e = e.CausedBy {
+			prefix := "  "
+			if e != c.Representative {
+				prefix = "  Caused by: "
+			}
+			msg, _, _ := strings.Cut(e.Message, "\n")
+			if msg != "" {
+				msg = ": " + msg
+			}
+			fmt.Fprintf(w, "%s%s%s\n", prefix, e.Class, msg)
+		}
+		root := c.Representative.RootCause()
+		if frames := root.Stacktrace.SuspectFrames(); len(frames) > 0 {
+			fmt.Fprintf(w, "    at %s\n", strings.TrimPrefix(root.Stacktrace[frames[0]].Raw, "at "))
+		}
+		for i, m := range c.Members {
+			if examples >= 0 && i >= examples {
+				fmt.Fprintf(w, "  ... and %d more\n", len(c.Members)-i)
+				break
+			}
+			fmt.Fprintf(w, "  - %s:%d (%.0f%%)\n", m.File, m.LineNo, m.Similarity*100)
+		}
+		if _, err = fmt.Fprintln(w); err != nil {
+			return
+		}
+	}
+	return
+}
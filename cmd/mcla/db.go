@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/GlobeMC/mcla"
+	"github.com/GlobeMC/mcla/ghdb"
+)
+
+func dbCommand(args []string) {
+	if len(args) == 0 {
+		printf("[ERROR]: Must give a db subcommand")
+		help()
+		os.Exit(exitUsage)
+	}
+	subcmd, args := args[0], args[1:]
+	switch subcmd {
+	case "lint":
+		if len(args) == 0 {
+			printf("[ERROR]: Must give the database directory as the argument")
+			os.Exit(exitUsage)
+		}
+		dbLint(args[0])
+	case "test":
+		flags := flag.NewFlagSet("db test", flag.ExitOnError)
+		threshold := flags.Float64("threshold", ghdb.DefaultSampleThreshold, "")
+		flags.Parse(args)
+		if flags.NArg() == 0 {
+			printf("[ERROR]: Must give the database directory as the argument")
+			os.Exit(exitUsage)
+		}
+		dbTest(flags.Arg(0), (float32)(*threshold))
+	case "new":
+		flags := flag.NewFlagSet("db new", flag.ExitOnError)
+		dir := flags.String("dir", ".", "")
+		pick := flags.Int("pick", 0, "")
+		solutions := flags.String("solutions", "", "")
+		flags.Parse(args)
+		if flags.NArg() == 0 {
+			printf("[ERROR]: Must give the log filename as the argument")
+			os.Exit(exitUsage)
+		}
+		dbNew(flags.Arg(0), *dir, *pick, *solutions)
+	case "search":
+		flags := flag.NewFlagSet("db search", flag.ExitOnError)
+		var query mcla.SearchQuery
+		tags := flags.String("tags", "", "")
+		flags.StringVar(&query.Class, "class", "", "")
+		flags.IntVar(&query.Limit, "limit", 10, "")
+		asJSON := flags.Bool("json", false, "")
+		flags.Parse(args)
+		if *tags != "" {
+			query.Tags = strings.Split(*tags, ",")
+		}
+		query.Text = strings.Join(flags.Args(), " ")
+		dbSearch(query, *asJSON)
+	default:
+		printf("[ERROR]: Unknown db command %q", subcmd)
+		help()
+		os.Exit(exitUsage)
+	}
+}
+
+func dbLint(dir string) {
+	report := ghdb.Lint(os.DirFS(dir))
+	errors, warnings := 0, 0
+	for _, issue := range report.Issues {
+		fmt.Println(issue)
+		if issue.Severity == ghdb.LintError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	printf("%d error(s), %d warning(s)", errors, warnings)
+	if errors > 0 {
+		os.Exit(exitErrorsFound)
+	}
+}
+
+func dbTest(dir string, threshold float32) {
+	results, err := ghdb.CheckSamples(context.Background(), os.DirFS(dir), threshold)
+	if err != nil {
+		printf("Error when checking samples: %v", err)
+		os.Exit(exitFailure)
+	}
+	failed := 0
+	for _, res := range results {
+		if res.Passed {
+			fmt.Println("PASS", res)
+		} else {
+			fmt.Println("FAIL", res)
+			failed++
+		}
+	}
+	printf("%d sample(s), %d failed", len(results), failed)
+	if failed > 0 {
+		os.Exit(exitErrorsFound)
+	}
+}
+
+// dbNew drafts a database entry from an exception in the log
+func dbNew(file string, dir string, pick int, solutions string) {
+	fd, err := openLogFile(file)
+	if err != nil {
+		printf("Error when opening file %q: %v", file, err)
+		os.Exit(exitFailure)
+	}
+	jerrs, err := mcla.ScanJavaErrors(fd)
+	fd.Close()
+	if err != nil {
+		printf("Error when scanning file %q: %v", file, err)
+		os.Exit(exitFailure)
+	}
+	var candidates []*mcla.JavaError
+	for _, je := range jerrs {
+		for ; je != nil; je = je.CausedBy {
+			candidates = append(candidates, je)
+		}
+	}
+	if len(candidates) == 0 {
+		printf("No any error was found")
+		os.Exit(exitFailure)
+	}
+	if pick == 0 {
+		for i, je := range candidates {
+			msg, _, _ := strings.Cut(je.Message, "\n")
+			fmt.Fprintf(os.Stderr, "[%d] line %d: %s: %s\n", i+1, je.LineNo, je.Class, msg)
+		}
+		fmt.Fprintf(os.Stderr, "Pick an exception [1-%d]: ", len(candidates))
+		line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if pick, err = strconv.Atoi(strings.TrimSpace(line)); err != nil {
+			printf("[ERROR]: %q is not a number", strings.TrimSpace(line))
+			os.Exit(exitUsage)
+		}
+	}
+	if pick < 1 || pick > len(candidates) {
+		printf("[ERROR]: Pick must between 1 and %d", len(candidates))
+		os.Exit(exitUsage)
+	}
+	je := candidates[pick-1]
+	desc := &mcla.ErrorDesc{
+		Error:     je.Class,
+		Solutions: []int{},
+	}
+	desc.Message, desc.Pattern = mcla.GeneralizeMessage(je.Message)
+	if solutions != "" {
+		for _, s := range strings.Split(solutions, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil {
+				printf("[ERROR]: Solution ID %q is not a number", s)
+				os.Exit(exitUsage)
+			}
+			desc.Solutions = append(desc.Solutions, id)
+		}
+	}
+	id, err := ghdb.AddErrorDesc(dir, desc)
+	if err != nil {
+		printf("Error when writing the entry: %v", err)
+		os.Exit(exitFailure)
+	}
+	if desc.Pattern != "" {
+		printf("Created errors/%d.json with pattern %q", id, desc.Pattern)
+	} else {
+		printf("Created errors/%d.json with message %q", id, desc.Message)
+	}
+}
+
+func dbSearch(query mcla.SearchQuery, asJSON bool) {
+	idx, err := mcla.BuildSearchIndex(defaultAnalyzer.DB)
+	if err != nil {
+		printf("Error when indexing the database: %v", err)
+		os.Exit(exitFailure)
+	}
+	hits := idx.Search(query)
+	for _, hit := range hits {
+		hit.Solution = hit.Solution.Localize(locale)
+	}
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(hits); err != nil {
+			printf("Error when encoding result as json: %v", err)
+			os.Exit(exitFailure)
+		}
+		return
+	}
+	if len(hits) == 0 {
+		printf("No solution was found")
+		return
+	}
+	for _, hit := range hits {
+		fmt.Printf("#%d (score %.2f) [%s]\n", hit.ID, hit.Score, strings.Join(hit.Solution.Tags, ", "))
+		text := mcla.RenderSolution(hit.Solution, nil, mcla.RenderText)
+		fmt.Printf("    %s\n", strings.ReplaceAll(text, "\n", "\n    "))
+		for _, e := range hit.Errors {
+			ref := strconv.Itoa(e.ID)
+			if e.Source != "" {
+				ref = e.Source + ":" + ref
+			}
+			fmt.Printf("    - %s %s: %s\n", ref, e.Error, e.Message+e.Pattern)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/GlobeMC/mcla"
+)
+
+func readLogFile(name string) ([]byte, error) {
+	fd, err := openLogFile(name)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	return io.ReadAll(fd)
+}
+
+// diffCommand compares two crash reports, or the errors of two logs.
+// It fails if only one of the files is a crash report, since a crash report and a log cannot be compared
+func diffCommand(args []string) {
+	if len(args) != 2 {
+		printf("[ERROR]: Must give the old and the new filenames as the arguments")
+		os.Exit(exitUsage)
+	}
+	var (
+		data      [2][]byte
+		reports   [2]*mcla.CrashReport
+		parseErrs [2]error
+		err       error
+	)
+	for i, name := range args {
+		if data[i], err = readLogFile(name); err != nil {
+			printf("Error when reading file %q: %v", name, err)
+			os.Exit(exitFailure)
+		}
+		reports[i], parseErrs[i] = mcla.ParseCrashReport(bytes.NewReader(data[i]))
+	}
+	if (parseErrs[0] == nil) != (parseErrs[1] == nil) {
+		report, other := 0, 1
+		if parseErrs[0] != nil {
+			report, other = 1, 0
+		}
+		if parseErrs[other] == io.EOF { // no crash report header
+			printf("[ERROR]: %q is a crash report, but %q is not", args[report], args[other])
+		} else {
+			printf("[ERROR]: %q is a crash report, but %q cannot be parsed: %v", args[report], args[other], parseErrs[other])
+		}
+		os.Exit(exitUsage)
+	}
+
+	var diff any
+	if parseErrs[0] == nil {
+		printf("[INFO]: Comparing as crash reports")
+		diff = mcla.DiffCrashReports(reports[0], reports[1])
+	} else {
+		printf("[INFO]: Comparing as logs")
+		var errs [2][]*mcla.JavaError
+		for i, name := range args {
+			if errs[i], err = mcla.ScanJavaErrors(bytes.NewReader(data[i])); err != nil {
+				printf("Error when scanning file %q: %v", name, err)
+				os.Exit(exitFailure)
+			}
+		}
+		diff = mcla.DiffLogErrors(errs[0], errs[1])
+	}
+
+	switch output {
+	case outputJSON, outputNDJSON:
+		err = newJSONEncoder().Encode(diff)
+	default:
+		switch d := diff.(type) {
+		case *mcla.CrashReportDiff:
+			err = writeCrashReportDiff(os.Stdout, d)
+		case *mcla.LogDiff:
+			err = writeLogDiff(os.Stdout, d)
+		}
+	}
+	if err != nil {
+		printf("Error when printing diff: %v", err)
+		os.Exit(exitFailure)
+	}
+}
+
+var diffMarks = map[mcla.DiffStatus]string{
+	mcla.DiffUnchanged: "=",
+	mcla.DiffAdded:     "+",
+	mcla.DiffRemoved:   "-",
+	mcla.DiffChanged:   "~",
+}
+
+func writeDiffEntry(w io.Writer, e mcla.DiffEntry) {
+	switch e.Status {
+	case mcla.DiffAdded:
+		fmt.Fprintf(w, "  + %s: %s\n", e.Key, e.New)
+	case mcla.DiffRemoved:
+		fmt.Fprintf(w, "  - %s: %s\n", e.Key, e.Old)
+	case mcla.DiffChanged:
+		fmt.Fprintf(w, "  ~ %s: %s -> %s\n", e.Key, e.Old, e.New)
+	default:
+		fmt.Fprintf(w, "  = %s\n", e.Key)
+	}
+}
+
+func writeCrashReportDiff(w io.Writer, d *mcla.CrashReportDiff) error {
+	if d.Description.Status == mcla.DiffChanged {
+		fmt.Fprintf(w, "Description changed:\n  - %s\n  + %s\n", d.Description.Old, d.Description.New)
+	} else {
+		fmt.Fprintf(w, "Description unchanged: %s\n", d.Description.New)
+	}
+	if d.SameError {
+		fmt.Fprintf(w, "\nError unchanged:\n")
+	} else {
+		fmt.Fprintf(w, "\nError changed:\n")
+	}
+	for _, e := range d.ErrorChain {
+		fmt.Fprintf(w, "  %s %s\n", diffMarks[e.Status], e.Key)
+	}
+	if len(d.Mods) > 0 {
+		fmt.Fprintf(w, "\nMods:\n")
+		for _, e := range d.Mods {
+			writeDiffEntry(w, e)
+		}
+	}
+	if len(d.Details) > 0 {
+		fmt.Fprintf(w, "\nSystem details:\n")
+		for _, e := range d.Details {
+			writeDiffEntry(w, e)
+		}
+	}
+	return nil
+}
+
+func writeLogDiff(w io.Writer, d *mcla.LogDiff) error {
+	for _, section := range []struct {
+		title  string
+		mark   string
+		errors []*mcla.ErrorDiff
+	}{
+		{"New errors", "+", d.New},
+		{"Fixed errors", "-", d.Fixed},
+		{"Unchanged errors", "=", d.Unchanged},
+	} {
+		fmt.Fprintf(w, "%s (%d):\n", section.title, len(section.errors))
+		for _, e := range section.errors {
+			fmt.Fprintf(w, "  %s %s (%d -> %d)\n", section.mark, e.Fingerprint, e.OldCount, e.NewCount)
+		}
+	}
+	return nil
+}
@@ -1,43 +1,152 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
-	"io"
 	"net/http"
-	"net/url"
+	"os"
+	"strings"
 
 	"github.com/GlobeMC/mcla"
 	"github.com/GlobeMC/mcla/ghdb"
 )
 
-type HTTPStatusErr struct {
-	URL        string
-	StatusCode int
+// the mirrors of https://github.com/kmcsr/mcla-db-dev, raw.githubusercontent.com is not reachable in some regions
+var ghRepoMirrors = []string{
+	"https://raw.githubusercontent.com/kmcsr/mcla-db-dev/main",
+	"https://cdn.jsdelivr.net/gh/kmcsr/mcla-db-dev@main",
+	"https://fastly.jsdelivr.net/gh/kmcsr/mcla-db-dev@main",
 }
 
-func (e *HTTPStatusErr) Error() string {
-	return fmt.Sprintf("HTTP status code error: %d when getting %q", e.StatusCode, e.URL)
+func httpDo(ctx context.Context, url string, req *ghdb.FetchRequest) (*ghdb.FetchResponse, error) {
+	hreq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if req.ETag != "" {
+		hreq.Header.Set("If-None-Match", req.ETag)
+	}
+	if req.LastModified != "" {
+		hreq.Header.Set("If-Modified-Since", req.LastModified)
+	}
+	res, err := http.DefaultClient.Do(hreq)
+	if err != nil {
+		return nil, err
+	}
+	switch res.StatusCode {
+	case http.StatusOK:
+		return &ghdb.FetchResponse{
+			Body:         res.Body,
+			ETag:         res.Header.Get("ETag"),
+			LastModified: res.Header.Get("Last-Modified"),
+		}, nil
+	case http.StatusNotModified:
+		res.Body.Close()
+		return &ghdb.FetchResponse{NotModified: true}, nil
+	}
+	res.Body.Close()
+	return nil, &ghdb.HTTPStatusErr{URL: res.Request.URL.String(), StatusCode: res.StatusCode}
 }
 
-var ghRepoPrefix = "https://raw.githubusercontent.com/kmcsr/mcla-db-dev/main"
+func isURL(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
 
-var defaultErrDB = &ghdb.ErrDB{
-	Cache: ghdb.NewInMemoryCache(),
-	Fetch: func(path string) (io.ReadCloser, error) {
-		path, err := url.JoinPath(ghRepoPrefix, path)
-		if err != nil {
-			return nil, err
-		}
-		res, err := http.DefaultClient.Get(path)
-		if err != nil {
-			return nil, err
-		}
-		if res.StatusCode != 200 {
-			res.Body.Close()
-			return nil, &HTTPStatusErr{res.Request.URL.String(), res.StatusCode}
+// newErrDB creates a database from either comma separated URL prefixes or a local directory
+func newErrDB(location string) *ghdb.ErrDB {
+	var fetcher ghdb.Fetcher
+	if isURL(location) {
+		fetcher = ghdb.NewMirrorFetcher(httpDo, strings.Split(location, ",")...)
+	} else {
+		fetcher = ghdb.FSFetcher{FS: os.DirFS(location)}
+	}
+	return &ghdb.ErrDB{
+		Cache:   ghdb.NewInMemoryCache(),
+		Fetcher: fetcher,
+	}
+}
+
+const publicDBLayerName = "public"
+
+// dbLayerList is the value of the repeatable `-db <name>=<url|dir>` flag
+type dbLayerList []mcla.DBLayer
+
+var _ flag.Value = (*dbLayerList)(nil)
+
+func (l *dbLayerList) String() string {
+	names := make([]string, len(*l))
+	for i, layer := range *l {
+		names[i] = layer.Name
+	}
+	return strings.Join(names, ",")
+}
+
+func (l *dbLayerList) Set(value string) error {
+	name, location, ok := strings.Cut(value, "=")
+	if !ok || name == "" || location == "" {
+		return fmt.Errorf("Database layer %q is not in <name>=<url|dir> format", value)
+	}
+	if name == publicDBLayerName {
+		return fmt.Errorf("Database layer name %q is reserved", name)
+	}
+	for _, layer := range *l {
+		if layer.Name == name {
+			return fmt.Errorf("Database layer %q is duplicated", name)
 		}
-		return res.Body, nil
-	},
+	}
+	*l = append(*l, mcla.DBLayer{
+		Name: name,
+		DB:   newErrDB(location),
+	})
+	return nil
+}
+
+// mirrorList is the value of the repeatable `-mirror <url>` flag
+type mirrorList []string
+
+var _ flag.Value = (*mirrorList)(nil)
+
+func (l *mirrorList) String() string {
+	return strings.Join(*l, ",")
 }
 
+func (l *mirrorList) Set(value string) error {
+	if !isURL(value) {
+		return fmt.Errorf("Mirror %q is not a http(s) URL", value)
+	}
+	*l = append(*l, value)
+	return nil
+}
+
+var (
+	dbLayerFlags dbLayerList
+	mirrorFlags  mirrorList
+	tagsFlag     string
+)
+
+var defaultErrDB = newErrDB(strings.Join(ghRepoMirrors, ","))
+
 var defaultAnalyzer = mcla.NewAnalyzer(defaultErrDB)
+
+func setupErrDB() error {
+	defaultAnalyzer.ResolveSolutions = true
+	defaultAnalyzer.Locale = locale
+	if tagsFlag != "" {
+		defaultAnalyzer.SolutionTags = strings.Split(tagsFlag, ",")
+	}
+	if len(mirrorFlags) > 0 {
+		fetcher, ok := defaultErrDB.Fetcher.(*ghdb.MirrorFetcher)
+		if !ok {
+			return fmt.Errorf("The default database does not support mirrors")
+		}
+		fetcher.SetMirrors(mirrorFlags)
+	}
+	if len(dbLayerFlags) > 0 {
+		defaultAnalyzer.DB = mcla.NewLayeredErrorDB(mcla.DBLayer{
+			Name: publicDBLayerName,
+			DB:   defaultErrDB,
+		}, dbLayerFlags...)
+	}
+	return nil
+}
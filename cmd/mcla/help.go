@@ -11,11 +11,84 @@ Under GNU AFFERO GENERAL PUBLIC LICENSE v3
 
 const HELP_MESSAGE = `
 Usage:
-   mcla <subcommand> [<subcmd args>...]
+   mcla [<flags>...] <subcommand> [<subcmd args>...]
+
+Flags:
+   -db <name>=<url|dir>
+       Add an error database layer on top of the public database.
+       Layers given later have higher priority. Can be given multiple times.
+       Comma separated URLs are used as mirrors of the same database.
+   -mirror <url>
+       Replace the mirrors of the public database, the first one is preferred.
+       Can be given multiple times.
+
+   -lang <locale>
+       The language of the solutions, e.g. zh-CN. Defaults to $LC_ALL, $LC_MESSAGES or $LANG.
+   -tags <tag,...>
+       Only show the solutions which have any of the tags.
+   -format text|markdown|json|ndjson|html
+       The output format of parseCrashReport, analyzeErrors and watch.
+       Defaults to colored text for terminals (set $NO_COLOR to disable colors), otherwise json.
+       html writes a single page report of all the files, which can be viewed offline.
+   -encoding auto|utf-8|utf-16le|utf-16be|gbk|shift_jis|windows-1251
+       The encoding of the logs, defaults to auto, which detects it by the BOM and the content.
+       After plain ASCII lines, it's detected again from the first non-ASCII text.
+       watch detects it once from the existing content, give it when the file is empty or plain ASCII.
+   -fail-on <condition,...>
+       Which errors are known fatal for the exit code of analyzeErrors, defaults to 0.9.
+       A condition is either a confidence like 0.8 or 80%, which any matched database entry reaches,
+       or tag:<tag>[:<confidence>] for the matched solutions with the tag, or none.
 
 Subcommands:
    - parseCrashReport <filename>
+       The file can also be a log like latest.log, every crash report printed in it is parsed
    - analyzeErrors [<filename>...]
+       The file can be a log (optionally gzipped), or a game instance directory, .zip, .tar.gz archive.
+       For an instance, logs/latest.log, logs/debug.log, logs/*.log.gz, crash-reports/*.txt and
+       hs_err_pid*.log are discovered and analyzed into one combined report.
+       All files are analyzed even if some of them failed, and the exit code is:
+         0 no error was found, 1 errors were found, 2 invalid arguments,
+         3 an error matched -fail-on, 4 a file cannot be read or analyzed
+   - watch [-all] [-interval <duration>] <filename>
+       Follow a growing log like latest.log, and print the new errors once they are written.
+       The existing errors are skipped unless -all is given
+   - redact [-o <file>] [-users <name,...>] [-report] <filename>
+       Replace the usernames, UUIDs, access tokens, IP addresses, home directories and world names
+       with placeholders like <username-1>, the lines are kept so the line numbers still match.
+       With -report, the file is parsed as a crash report and written as JSON
+   - solution [-format text|markdown|html] <id> [<key>=<value>...]
+       Render a solution, the values are used to expand the ${key} placeholders
+   - db lint <dir>
+       Validate a database directory before publishing it
+   - db test [-threshold <0-1>] <dir>
+       Check that every entry is the top match of its sample logs
+   - db search [-tags <tag,...>] [-class <exception>] [-limit <n>] [-json] [<text>...]
+       Search the solutions by tags, exception class and text
+   - db new [-dir <dir>] [-pick <n>] [-solutions <id,...>] <logfile>
+       Draft a new entry from an exception in the log, and update version.json.
+       The variable parts of the message, like names and numbers, are replaced by wildcards
+   - stats [-top <n>] [-csv] [-j <workers>] <dir>
+       Analyze every crash report and log in the directory in parallel, and report the most
+       frequent errors, matched solutions, suspected mods, and the Minecraft, loader and Java versions
+       of the crash reports. Printed as JSON with -format json, or as CSV with -csv.
+       The .txt files without the crash report header, like options.txt, are skipped
+   - cluster [-threshold <0-1>] [-unknown] [-examples <n>] [-j <workers>] <dir>
+       Group the similar errors of the crash reports and logs in the directory, even if their messages
+       differ in coordinates, entity IDs or hashes. With -unknown, only the clusters without any
+       matched solution are listed, they are worth writing new database entries for
+   - diff <old> <new>
+       Compare two crash reports: the description, the error chain, the added, removed and updated mods,
+       and the system details. Otherwise the files are compared as logs, and the errors are listed
+       as new, fixed or unchanged. A crash report cannot be compared with a log
+   - serve [-addr <host:port>] [-max-size <bytes>] [-timeout <duration>]
+       Start a HTTP server which accepts logs as the request body or a multipart file:
+         POST /api/crashreport     parse a crash report
+         POST /api/errors          scan the java errors in a log
+         POST /api/analyze         analyze a log, streamed as NDJSON, or SSE with ?stream=sse
+         GET  /api/solution/<id>   get a solution, with ?lang=, ?format= and placeholder values
+
+The other subcommands exit with 2 for invalid arguments, and 4 when they failed.
+db lint and db test exit with 1 when the database has errors.
 `
 
 func help() {
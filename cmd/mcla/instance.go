@@ -0,0 +1,122 @@
+package main
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/GlobeMC/mcla"
+)
+
+// isInstance reports whether the path should be analyzed as a whole game instance
+func isInstance(name string) bool {
+	if isArchive(name) {
+		return true
+	}
+	stat, err := os.Stat(name)
+	return err == nil && stat.IsDir()
+}
+
+func isArchive(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, ".zip") || strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz")
+}
+
+// openInstance discovers the logs in a directory, a zip or a gzipped tar archive
+func openInstance(name string) (files []*mcla.InstanceFile, closer io.Closer, err error) {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		zr, err := zip.OpenReader(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if files, err = mcla.FindInstanceFiles(zr); err != nil {
+			zr.Close()
+			return nil, nil, err
+		}
+		return files, zr, nil
+	case strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz"):
+		fd, err := os.Open(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer fd.Close()
+		gr, err := gzip.NewReader(fd)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer gr.Close()
+		files, err = mcla.FindTarFiles(gr)
+		return files, io.NopCloser(nil), err
+	}
+	files, err = mcla.FindInstanceFiles(os.DirFS(name))
+	return files, io.NopCloser(nil), err
+}
+
+func analyzeInstanceAndOutput(name string, summary *runSummary) (err error) {
+	files, closer, err := openInstance(name)
+	if err != nil {
+		return fmt.Errorf("Error when opening %q: %w", name, err)
+	}
+	defer closer.Close()
+	if len(files) == 0 {
+		printf("No any log was found in %q", name)
+		return nil
+	}
+	report, err := defaultAnalyzer.AnalyzeInstance(context.Background(), files)
+	if err != nil {
+		return fmt.Errorf("Error when analyzing %q: %w", name, err)
+	}
+	for _, file := range report.Files {
+		if file.Failure != "" {
+			summary.failed++
+			printf("[ERROR]: Cannot analyze %q in %q: %s", file.Name, name, file.Failure)
+		}
+		printWarnings(file.Name, file.Warnings)
+		if file.JVMCrash != nil {
+			summary.errors++
+		}
+		for _, res := range file.Errors {
+			summary.addResult(res)
+			printUnresolved(file.Name, res)
+		}
+	}
+	if err = printValue(name, report); err != nil {
+		return fmt.Errorf("Error when printing report: %w", err)
+	}
+	return nil
+}
+
+// decodedFile is a decoded log file, the analyzer will not detect its encoding again
+type decodedFile struct {
+	*mcla.DecodedReader
+	io.Closer
+}
+
+// openLogFile opens a single log file, and decompresses and decodes it with the -encoding flag
+func openLogFile(name string) (io.ReadCloser, error) {
+	fd, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	var r io.Reader = fd
+	if strings.HasSuffix(strings.ToLower(name), ".gz") {
+		if r, err = gzip.NewReader(fd); err != nil {
+			fd.Close()
+			return nil, err
+		}
+	}
+	dr, err := mcla.NewDecodedReader(r, encodingFlag)
+	if err != nil {
+		fd.Close()
+		return nil, err
+	}
+	return decodedFile{dr, fd}, nil
+}
+
+var encodingFlag string
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestOpenLogFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "latest.log")
+	data, err := charmap.Windows1251.NewEncoder().String("[00:00:00] [main/INFO]: Привет\n")
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if err = os.WriteFile(name, ([]byte)(data), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	defer func(enc string) { encodingFlag = enc }(encodingFlag)
+
+	encodingFlag = "windows-1251"
+	fd, err := openLogFile(name)
+	if err != nil {
+		t.Fatalf("openLogFile: %v", err)
+	}
+	buf, err := io.ReadAll(fd)
+	fd.Close()
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if s := (string)(buf); s != "[00:00:00] [main/INFO]: Привет\n" {
+		t.Errorf("Unexpected decoded log %q", s)
+	}
+
+	encodingFlag = "ebcdic"
+	if fd, err = openLogFile(name); err == nil {
+		fd.Close()
+		t.Errorf("Expect an error for the unsupported encoding")
+	}
+}
@@ -1,8 +1,9 @@
 package main
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
 
@@ -17,65 +18,139 @@ func printf(format string, args ...any) {
 }
 
 func main() {
-	if len(os.Args) <= 1 {
+	flag.Usage = help
+	flag.Var(&dbLayerFlags, "db", "")
+	flag.Var(&mirrorFlags, "mirror", "")
+	flag.StringVar(&locale, "lang", defaultLocale(), "")
+	flag.StringVar(&tagsFlag, "tags", "", "")
+	flag.StringVar(&formatFlag, "format", "", "")
+	flag.StringVar(&failOnFlag, "fail-on", defaultFailOn, "")
+	flag.StringVar(&encodingFlag, "encoding", "auto", "")
+	flag.Parse()
+	args := flag.Args()
+	if len(args) == 0 {
 		help()
 		return
 	}
-	subcmd := os.Args[1]
+	if err := setupErrDB(); err != nil {
+		printf("[ERROR]: %v", err)
+		os.Exit(exitUsage)
+	}
+	if err := setupOutput(); err != nil {
+		printf("[ERROR]: %v", err)
+		os.Exit(exitUsage)
+	}
+	if encodingFlag != "auto" && mcla.NormalizeEncoding(encodingFlag) == "" {
+		printf("[ERROR]: Unsupported encoding %q", encodingFlag)
+		os.Exit(exitUsage)
+	}
+	// the files of instances, stats and cluster are opened by the analyzer
+	defaultAnalyzer.Encoding = encodingFlag
+	var err error
+	if defaultFailPolicy, err = parseFailPolicy(failOnFlag); err != nil {
+		printf("[ERROR]: %v", err)
+		os.Exit(exitUsage)
+	}
+	subcmd := args[0]
 	switch subcmd {
 	case "parseCrashReport":
-		if len(os.Args) <= 2 {
+		if len(args) <= 1 {
 			printf("[ERROR]: Must give the crashreport's filename as the second argument")
-			os.Exit(1)
+			os.Exit(exitUsage)
 		}
-		filename := os.Args[2]
-		fd, err := os.Open(filename)
+		filename := args[1]
+		data, err := readLogFile(filename)
 		if err != nil {
 			printf("Error when opening report file: %v", err)
-			os.Exit(1)
+			os.Exit(exitFailure)
 		}
-		report, err := mcla.ParseCrashReport(fd)
-		fd.Close()
-		if err != nil {
-			printf("Error when parsing report file: %v", err)
-			os.Exit(1)
+		// a log like latest.log may contain multiple crash reports
+		var reports []*mcla.CrashReport
+		if embedded, err := mcla.ParseCrashReports(bytes.NewReader(data)); err == nil {
+			for _, r := range embedded {
+				reports = append(reports, r.CrashReport)
+			}
 		}
-		encoder := json.NewEncoder(os.Stdout)
-		encoder.SetEscapeHTML(false)
-		encoder.SetIndent("", "  ")
-		if err = encoder.Encode(report); err != nil {
-			printf("\nError when encoding report file as json: %v", err)
-			os.Exit(1)
+		if len(reports) == 0 {
+			report, err := mcla.ParseCrashReport(bytes.NewReader(data))
+			if err != nil {
+				printf("Error when parsing report file: %v", err)
+				os.Exit(exitFailure)
+			}
+			reports = append(reports, report)
+		}
+		for _, report := range reports {
+			if output == outputHTML {
+				// the HTML report shows the matched solutions of the report's error
+				res, err := defaultAnalyzer.AnalyzeCrashReport(report)
+				if err != nil {
+					printf("Error when analyzing report file: %v", err)
+					os.Exit(exitFailure)
+				}
+				for _, e := range res.Errors {
+					e.File = filename
+					printUnresolved(filename, e)
+				}
+				htmlReport.AddCrashReport(filename, report, res.Errors)
+				continue
+			}
+			if err = printValue(filename, report); err != nil {
+				printf("\nError when printing report file: %v", err)
+				os.Exit(exitFailure)
+			}
 		}
 	case "analyzeErrors":
-		if len(os.Args) <= 2 {
+		if len(args) <= 1 {
 			return
 		}
-		files := os.Args[2:]
-		for _, name := range files {
-			analysisAndOutput(name)
-		}
+		analyzeErrorsCommand(args[1:])
+	case "solution":
+		solutionCommand(args[1:])
+	case "db":
+		dbCommand(args[1:])
+	case "watch":
+		watchCommand(args[1:])
+	case "redact":
+		redactCommand(args[1:])
+	case "serve":
+		serveCommand(args[1:])
+	case "stats":
+		statsCommand(args[1:])
+	case "cluster":
+		clusterCommand(args[1:])
+	case "diff":
+		diffCommand(args[1:])
 	case "help":
 		help()
 	default:
 		printf("[ERROR]: Unknown command %q", subcmd)
 		help()
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
+	finishOutput()
 }
 
-func analysisAndOutput(file string) {
-	fd, err := os.Open(file)
+var locale string
+
+// defaultLocale reads the locale from the environment variables like other POSIX programs
+func defaultLocale() string {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(key); v != "" {
+			return mcla.NormalizeLocale(v)
+		}
+	}
+	return ""
+}
+
+func analysisAndOutput(file string, summary *runSummary) (err error) {
+	fd, err := openLogFile(file)
 	if err != nil {
-		printf("Error when opening file %q: %v", file, err)
-		os.Exit(1)
+		return fmt.Errorf("Error when opening file %q: %w", file, err)
 	}
 	defer fd.Close()
 	result, ctx := defaultAnalyzer.DoLogStream(context.Background(), fd)
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetEscapeHTML(false)
-	encoder.SetIndent("", "  ")
-	ok := false
+	printer := newResultPrinter(file, true)
+	found := false
 LOOP_RES:
 	for {
 		select {
@@ -83,20 +158,35 @@ LOOP_RES:
 			if res == nil { // done
 				break LOOP_RES
 			}
-			ok = true
+			found = true
 			res.File = file
-			if err = encoder.Encode(res); err != nil {
-				printf("\nError when encoding report file as json: %v", err)
-				os.Exit(1)
+			summary.addResult(res)
+			printUnresolved(file, res)
+			if err = printer.Add(res); err != nil {
+				return fmt.Errorf("Error when printing result: %w", err)
 			}
 		case <-ctx.Done():
-			err := context.Cause(ctx)
-			printf("Error when analyzing file %q: %v", file, err)
-			os.Exit(1)
+			return fmt.Errorf("Error when analyzing file %q: %w", file, context.Cause(ctx))
 		}
 	}
-	if !ok {
-		printf("No any error was found")
-		os.Exit(1)
+	if err = printer.Done(); err != nil {
+		return fmt.Errorf("Error when printing result: %w", err)
+	}
+	printWarnings(file, mcla.LogWarnings(ctx))
+	if !found {
+		printf("No any error was found in %q", file)
+	}
+	return nil
+}
+
+func printWarnings(file string, warnings []mcla.ScanWarning) {
+	for _, w := range warnings {
+		printf("[WARN]: %s:%d: %s", file, w.LineNo, w.Message)
+	}
+}
+
+func printUnresolved(file string, res *mcla.ErrorResult) {
+	for _, u := range res.UnresolvedSolutions {
+		printf("[WARN]: %s:%d: Cannot get solution %d: %s", file, res.Error.LineNo, u.ID, u.Error)
 	}
 }
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/GlobeMC/mcla"
+)
+
+type outputFormat string
+
+const (
+	outputText     outputFormat = "text"
+	outputMarkdown outputFormat = "markdown"
+	outputJSON     outputFormat = "json"
+	outputNDJSON   outputFormat = "ndjson"
+	outputHTML     outputFormat = "html"
+)
+
+var (
+	formatFlag string
+	output     outputFormat
+	// htmlReport collects the results of all files, and is written by finishOutput
+	htmlReport *mcla.HTMLReport
+)
+
+func isTerminal(fd *os.File) bool {
+	stat, err := fd.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
+// setupOutput parses the -format flag, it defaults to text for terminals,
+// and JSON when the output is piped to other programs
+func setupOutput() error {
+	switch f := (outputFormat)(strings.ToLower(formatFlag)); f {
+	case "":
+		if isTerminal(os.Stdout) {
+			output = outputText
+		} else {
+			output = outputJSON
+		}
+	case "md":
+		output = outputMarkdown
+	case outputText, outputMarkdown, outputJSON, outputNDJSON:
+		output = f
+	case outputHTML:
+		output = f
+		htmlReport = mcla.NewHTMLReport("Minecraft Log Analysis", nil)
+	default:
+		return fmt.Errorf("Unknown output format %q", formatFlag)
+	}
+	return nil
+}
+
+// finishOutput writes the outputs which need all the results
+func finishOutput() {
+	if htmlReport == nil || len(htmlReport.Files) == 0 {
+		return
+	}
+	if err := htmlReport.Render(os.Stdout); err != nil {
+		printf("\nError when rendering HTML report: %v", err)
+		os.Exit(exitFailure)
+	}
+	htmlReport = nil
+}
+
+func newTextRenderer() *mcla.TextRenderer {
+	if output == outputMarkdown {
+		return &mcla.TextRenderer{Format: mcla.RenderMarkdown}
+	}
+	return &mcla.TextRenderer{
+		Format: mcla.RenderText,
+		Color:  isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == "",
+	}
+}
+
+func newJSONEncoder() *json.Encoder {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetEscapeHTML(false)
+	if output != outputNDJSON {
+		encoder.SetIndent("", "  ")
+	}
+	return encoder
+}
+
+// printValue prints the value of the file as JSON, or with the text renderer
+func printValue(name string, value any) (err error) {
+	switch output {
+	case outputJSON, outputNDJSON:
+		return newJSONEncoder().Encode(value)
+	case outputHTML:
+		// the crash reports are added with their analysis results by parseCrashReport
+		v, ok := value.(*mcla.InstanceReport)
+		if !ok {
+			return fmt.Errorf("Unexpected value type %T", value)
+		}
+		htmlReport.Files = append(htmlReport.Files, v.Files...)
+		return nil
+	}
+	r := newTextRenderer()
+	switch v := value.(type) {
+	case *mcla.CrashReport:
+		return r.RenderCrashReport(os.Stdout, v)
+	case *mcla.InstanceReport:
+		return r.RenderInstanceReport(os.Stdout, v)
+	}
+	return fmt.Errorf("Unexpected value type %T", value)
+}
+
+// resultPrinter prints the ErrorResults of a stream.
+// In JSON formats every result is printed once received, and the text formats print the whole cause chains.
+// If sorted is true, the chains are printed by their line numbers when the stream is done.
+// The HTML format only adds the results to htmlReport
+type resultPrinter struct {
+	name      string
+	sorted    bool
+	encoder   *json.Encoder
+	renderer  *mcla.TextRenderer
+	collector *mcla.ChainCollector
+	results   []*mcla.ErrorResult
+}
+
+func newResultPrinter(name string, sorted bool) (p *resultPrinter) {
+	p = &resultPrinter{name: name, sorted: sorted}
+	switch output {
+	case outputJSON, outputNDJSON:
+		p.encoder = newJSONEncoder()
+	case outputHTML:
+		p.sorted = true
+	default:
+		p.renderer = newTextRenderer()
+		p.collector = mcla.NewChainCollector()
+	}
+	return
+}
+
+func (p *resultPrinter) Add(res *mcla.ErrorResult) error {
+	if p.encoder != nil {
+		return p.encoder.Encode(res)
+	}
+	if p.sorted {
+		p.results = append(p.results, res)
+		return nil
+	}
+	if chain, ok := p.collector.Add(res); ok {
+		return p.renderer.RenderChain(os.Stdout, chain)
+	}
+	return nil
+}
+
+// Done prints the remaining results
+func (p *resultPrinter) Done() (err error) {
+	if p.encoder != nil {
+		return
+	}
+	if output == outputHTML {
+		htmlReport.AddLog(p.name, p.results)
+		p.results = nil
+		return
+	}
+	chains := p.collector.Flush()
+	if p.sorted {
+		chains = mcla.GroupChainResults(p.results)
+		p.results = nil
+	}
+	for _, chain := range chains {
+		if err = p.renderer.RenderChain(os.Stdout, chain); err != nil {
+			return
+		}
+	}
+	return
+}
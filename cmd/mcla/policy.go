@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/GlobeMC/mcla"
+)
+
+// The exit codes of analyzeErrors, a larger code takes precedence when analyzing multiple files.
+// The other commands exit with exitUsage for invalid arguments, and exitFailure when they failed
+const (
+	exitNoErrors    = 0 // no error was found
+	exitErrorsFound = 1 // some errors were found, but none of them matched the -fail-on policy
+	exitUsage       = 2 // same as the flag package
+	exitKnownFatal  = 3 // an error matched the -fail-on policy
+	exitFailure     = 4 // a file cannot be read or analyzed
+)
+
+const defaultFailOn = "0.9"
+
+var failOnFlag string
+
+// failPolicy decides which errors are known to be fatal
+type failPolicy struct {
+	// minMatch is the confidence of any matched entry to fail, 0 means disabled
+	minMatch float32
+	// tags maps the solution tags to the confidence to fail
+	tags map[string]float32
+}
+
+func parseConfidence(s string) (float32, error) {
+	percent := strings.HasSuffix(s, "%")
+	v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 32)
+	if err != nil {
+		return 0, fmt.Errorf("Confidence %q is not a number", s)
+	}
+	if percent {
+		v /= 100
+	}
+	if v < 0 || v > 1 {
+		return 0, fmt.Errorf("Confidence %q is out of range 0-1", s)
+	}
+	return (float32)(v), nil
+}
+
+// parseFailPolicy parses comma separated conditions, any of them makes the error fatal:
+//
+//	<confidence>            an entry matched with at least the confidence, e.g. 0.8 or 80%
+//	tag:<tag>[:<confidence>] a solution with the tag matched, with at least the confidence if given
+//	none                    never fail
+func parseFailPolicy(s string) (p *failPolicy, err error) {
+	p = &failPolicy{
+		tags: make(map[string]float32),
+	}
+	for _, cond := range strings.Split(s, ",") {
+		cond = strings.TrimSpace(cond)
+		switch {
+		case cond == "" || cond == "none":
+		case strings.HasPrefix(cond, "tag:"):
+			tag, conf, ok := strings.Cut(cond[len("tag:"):], ":")
+			if tag == "" {
+				return nil, fmt.Errorf("Tag condition %q is empty", cond)
+			}
+			var match float32
+			if ok {
+				if match, err = parseConfidence(conf); err != nil {
+					return nil, err
+				}
+			}
+			p.tags[strings.ToLower(tag)] = match
+		default:
+			if p.minMatch, err = parseConfidence(cond); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return
+}
+
+// isFatal decides on the matched entries, so an error is still fatal when its solutions are not resolved.
+// The tags are only known from the resolved solutions
+func (p *failPolicy) isFatal(res *mcla.ErrorResult) bool {
+	if p.minMatch > 0 {
+		for _, m := range res.Matched {
+			if m.Match >= p.minMatch {
+				return true
+			}
+		}
+	}
+	for _, sol := range res.Solutions {
+		for _, tag := range sol.Solution.Tags {
+			if match, ok := p.tags[strings.ToLower(tag)]; ok && sol.Match >= match {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var defaultFailPolicy *failPolicy
+
+// runSummary counts the results of all files
+type runSummary struct {
+	files  int
+	failed int
+	errors int
+	fatal  int
+}
+
+func (s *runSummary) addResult(res *mcla.ErrorResult) {
+	s.errors++
+	if defaultFailPolicy.isFatal(res) {
+		s.fatal++
+	}
+}
+
+func (s *runSummary) exitCode() int {
+	switch {
+	case s.failed > 0:
+		return exitFailure
+	case s.fatal > 0:
+		return exitKnownFatal
+	case s.errors > 0:
+		return exitErrorsFound
+	}
+	return exitNoErrors
+}
+
+func (s *runSummary) print() {
+	printf("[INFO]: Analyzed %d file(s): %d error(s), %d known fatal, %d failed", s.files, s.errors, s.fatal, s.failed)
+}
+
+// analyzeErrorsCommand analyzes every file even if some of them failed
+func analyzeErrorsCommand(files []string) {
+	var summary runSummary
+	for _, name := range files {
+		summary.files++
+		var err error
+		if isInstance(name) {
+			err = analyzeInstanceAndOutput(name, &summary)
+		} else {
+			err = analysisAndOutput(name, &summary)
+		}
+		if err != nil {
+			summary.failed++
+			printf("[ERROR]: %v", err)
+		}
+	}
+	finishOutput()
+	summary.print()
+	os.Exit(summary.exitCode())
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/GlobeMC/mcla"
+)
+
+func TestFailPolicyIsFatal(t *testing.T) {
+	matched := &mcla.ErrorResult{
+		Matched: []mcla.SolutionPossibility{{ErrorDesc: &mcla.ErrorDesc{ID: 1, Solutions: []int{1}}, Match: 0.95}},
+		// the solution cannot be fetched
+		UnresolvedSolutions: []mcla.UnresolvedSolution{{ID: 1, Error: "timeout"}},
+	}
+	weak := &mcla.ErrorResult{
+		Matched: []mcla.SolutionPossibility{{ErrorDesc: &mcla.ErrorDesc{ID: 2, Solutions: []int{2}}, Match: 0.5}},
+		Solutions: []*mcla.ResolvedSolution{
+			{ID: 2, Match: 0.5, Solution: &mcla.SolutionDesc{Tags: []string{"Crash"}}},
+		},
+	}
+	cases := []struct {
+		failOn string
+		res    *mcla.ErrorResult
+		fatal  bool
+	}{
+		{"0.9", matched, true},
+		{"0.9", weak, false},
+		{"none", matched, false},
+		{"tag:crash", weak, true},
+		{"tag:crash:0.6", weak, false},
+		{"tag:crash", matched, false},
+	}
+	for _, c := range cases {
+		p, err := parseFailPolicy(c.failOn)
+		if err != nil {
+			t.Fatalf("parseFailPolicy(%q): %v", c.failOn, err)
+		}
+		if fatal := p.isFatal(c.res); fatal != c.fatal {
+			t.Errorf("Expect isFatal is %v with %q for %v, got %v", c.fatal, c.failOn, c.res.Matched[0].Match, fatal)
+		}
+	}
+}
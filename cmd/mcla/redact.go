@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/GlobeMC/mcla"
+)
+
+// redactCommand writes the log without the personal information, so it can be published
+func redactCommand(args []string) {
+	flags := flag.NewFlagSet("redact", flag.ExitOnError)
+	outFile := flags.String("o", "", "")
+	users := flags.String("users", "", "")
+	report := flags.Bool("report", false, "")
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		printf("[ERROR]: Must give the log filename as the argument")
+		os.Exit(exitUsage)
+	}
+	r := mcla.NewRedactor()
+	if *users != "" {
+		r.Usernames = strings.Split(*users, ",")
+	}
+	fd, err := openLogFile(flags.Arg(0))
+	if err != nil {
+		printf("Error when opening file %q: %v", flags.Arg(0), err)
+		os.Exit(exitFailure)
+	}
+	defer fd.Close()
+	out := os.Stdout
+	if *outFile != "" {
+		if out, err = os.Create(*outFile); err != nil {
+			printf("Error when creating file %q: %v", *outFile, err)
+			os.Exit(exitFailure)
+		}
+		defer out.Close()
+	}
+	if *report {
+		crash, err := mcla.ParseCrashReport(fd)
+		if err != nil {
+			printf("Error when parsing report file: %v", err)
+			os.Exit(exitFailure)
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(r.RedactCrashReport(crash))
+	} else {
+		err = r.RedactLog(out, fd)
+	}
+	if err != nil {
+		printf("Error when redacting %q: %v", flags.Arg(0), err)
+		os.Exit(exitFailure)
+	}
+	counts := r.Counts()
+	kinds := make([]string, 0, len(counts))
+	for kind, n := range counts {
+		kinds = append(kinds, (string)(kind)+"="+strconv.Itoa(n))
+	}
+	slices.Sort(kinds)
+	printf("[INFO]: Redacted %s", strings.Join(kinds, ", "))
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GlobeMC/mcla"
+)
+
+const (
+	defaultServeAddr    = "127.0.0.1:8080"
+	defaultServeMaxSize = 16 << 20 // 16 MiB
+	defaultServeTimeout = time.Minute
+	// serveWriteMargin is the extra time to write the response after the request timeout,
+	// so the timeout error can still be sent
+	serveWriteMargin = 10 * time.Second
+)
+
+// serveCommand starts a HTTP server, so bots and web pages can use the analyzer without spawning processes
+func serveCommand(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", defaultServeAddr, "")
+	maxSize := flags.Int64("max-size", defaultServeMaxSize, "")
+	timeout := flags.Duration("timeout", defaultServeTimeout, "")
+	flags.Parse(args)
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           newServeHandler(defaultAnalyzer, *maxSize, *timeout),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       *timeout,
+	}
+	if *timeout > 0 {
+		server.WriteTimeout = *timeout + serveWriteMargin
+	}
+	printf("[INFO]: Listening at http://%s", *addr)
+	if err := server.ListenAndServe(); err != nil {
+		printf("[ERROR]: %v", err)
+		os.Exit(exitFailure)
+	}
+}
+
+type serveHandler struct {
+	analyzer *mcla.Analyzer
+	maxSize  int64
+	timeout  time.Duration
+}
+
+func newServeHandler(analyzer *mcla.Analyzer, maxSize int64, timeout time.Duration) http.Handler {
+	h := &serveHandler{
+		analyzer: analyzer,
+		maxSize:  maxSize,
+		timeout:  timeout,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/crashreport", h.limit(h.handleCrashReport))
+	mux.HandleFunc("POST /api/errors", h.limit(h.handleErrors))
+	mux.HandleFunc("POST /api/analyze", h.limit(h.handleAnalyze))
+	mux.HandleFunc("GET /api/solution/{id}", h.limit(h.handleSolution))
+	mux.HandleFunc("GET /api/version", func(rw http.ResponseWriter, req *http.Request) {
+		writeJSON(rw, http.StatusOK, map[string]string{"version": version})
+	})
+	return mux
+}
+
+// limit applies the request body size limit and the request timeout
+func (h *serveHandler) limit(handler http.HandlerFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if h.maxSize > 0 {
+			req.Body = http.MaxBytesReader(rw, req.Body, h.maxSize)
+		}
+		if h.timeout > 0 {
+			ctx, cancel := context.WithTimeout(req.Context(), h.timeout)
+			defer cancel()
+			req = req.WithContext(ctx)
+		}
+		handler(rw, req)
+	}
+}
+
+func writeJSON(rw http.ResponseWriter, status int, value any) {
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	rw.WriteHeader(status)
+	encoder := json.NewEncoder(rw)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(value)
+}
+
+func writeError(rw http.ResponseWriter, status int, err error) {
+	writeJSON(rw, status, map[string]string{"error": err.Error()})
+}
+
+// errorStatus picks the status code for an error which occurred while handling the request body
+func errorStatus(err error) int {
+	var maxErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxErr):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, mcla.ErrEntryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, mcla.ErrCrashReportIncomplete):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
+// contextReader fails once the context is done, so the parsers without a context are stopped by the request timeout
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(buf []byte) (int, error) {
+	if err := context.Cause(r.ctx); err != nil {
+		return 0, err
+	}
+	return r.r.Read(buf)
+}
+
+// requestBody returns the uploaded log, which is either the raw body or the first file of a multipart form,
+// the reading is stopped when the request is done
+func requestBody(req *http.Request) (io.Reader, error) {
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return contextReader{req.Context(), req.Body}, nil
+	}
+	mr, err := req.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			if err == io.EOF {
+				return nil, errors.New("No file was uploaded")
+			}
+			return nil, err
+		}
+		if part.FileName() != "" {
+			return contextReader{req.Context(), part}, nil
+		}
+		part.Close()
+	}
+}
+
+func (h *serveHandler) handleCrashReport(rw http.ResponseWriter, req *http.Request) {
+	body, err := requestBody(req)
+	if err != nil {
+		writeError(rw, http.StatusBadRequest, err)
+		return
+	}
+	report, err := mcla.ParseCrashReport(body)
+	if err == nil {
+		// the parser may return the part read before the timeout
+		err = context.Cause(req.Context())
+	}
+	if err != nil {
+		writeError(rw, errorStatus(err), err)
+		return
+	}
+	writeJSON(rw, http.StatusOK, report)
+}
+
+func (h *serveHandler) handleErrors(rw http.ResponseWriter, req *http.Request) {
+	body, err := requestBody(req)
+	if err != nil {
+		writeError(rw, http.StatusBadRequest, err)
+		return
+	}
+	errs, err := mcla.ScanJavaErrors(body)
+	if err == nil {
+		err = context.Cause(req.Context())
+	}
+	if err != nil {
+		writeError(rw, errorStatus(err), err)
+		return
+	}
+	if errs == nil {
+		errs = make([]*mcla.JavaError, 0)
+	}
+	writeJSON(rw, http.StatusOK, errs)
+}
+
+// wantsSSE reports whether the client asked for server-sent events instead of NDJSON
+func wantsSSE(req *http.Request) bool {
+	if req.URL.Query().Get("stream") == "sse" {
+		return true
+	}
+	return strings.Contains(req.Header.Get("Accept"), "text/event-stream")
+}
+
+// handleAnalyze streams the results once they are available, one JSON object per line,
+// or as `result` events followed by an `end` or `error` event if the client accepts SSE
+func (h *serveHandler) handleAnalyze(rw http.ResponseWriter, req *http.Request) {
+	body, err := requestBody(req)
+	if err != nil {
+		writeError(rw, http.StatusBadRequest, err)
+		return
+	}
+	sse := wantsSSE(req)
+	if sse {
+		rw.Header().Set("Content-Type", "text/event-stream")
+		rw.Header().Set("Cache-Control", "no-cache")
+	} else {
+		rw.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	rw.Header().Set("X-Content-Type-Options", "nosniff")
+	flusher, _ := rw.(http.Flusher)
+
+	result, ctx := h.analyzer.DoLogStream(req.Context(), body)
+	started := false
+	send := func(event string, value any) {
+		buf, err := json.Marshal(value)
+		if err != nil {
+			return
+		}
+		if sse {
+			fmt.Fprintf(rw, "event: %s\ndata: %s\n\n", event, buf)
+		} else {
+			rw.Write(buf)
+			rw.Write([]byte{'\n'})
+		}
+		started = true
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	for {
+		select {
+		case res := <-result:
+			if res == nil { // done
+				if sse {
+					send("end", struct{}{})
+				} else if !started {
+					rw.WriteHeader(http.StatusOK)
+				}
+				return
+			}
+			send("result", res)
+		case <-ctx.Done():
+			err := context.Cause(ctx)
+			if !started {
+				writeError(rw, errorStatus(err), err)
+				return
+			}
+			// the status code was sent, so report the error in the stream
+			send("error", map[string]string{"error": err.Error()})
+			return
+		}
+	}
+}
+
+type solutionResponse struct {
+	ID       int                `json:"id"`
+	Solution *mcla.SolutionDesc `json:"solution"`
+	Rendered string             `json:"rendered"`
+}
+
+// handleSolution returns the localized solution, the query parameters other than
+// `lang` and `format` are used to expand the placeholders
+func (h *serveHandler) handleSolution(rw http.ResponseWriter, req *http.Request) {
+	id, err := strconv.Atoi(req.PathValue("id"))
+	if err != nil {
+		writeError(rw, http.StatusBadRequest, fmt.Errorf("Solution ID %q is not a number", req.PathValue("id")))
+		return
+	}
+	query := req.URL.Query()
+	format, err := mcla.ParseRenderFormat(query.Get("format"))
+	if err != nil {
+		writeError(rw, http.StatusBadRequest, err)
+		return
+	}
+	lang := h.analyzer.Locale
+	if query.Has("lang") {
+		lang = mcla.NormalizeLocale(query.Get("lang"))
+	}
+	data := make(map[string]any)
+	for key, values := range query {
+		if key != "lang" && key != "format" && len(values) > 0 {
+			data[key] = values[0]
+		}
+	}
+	sol, err := h.analyzer.GetLocalizedSolution(id, lang)
+	if err != nil {
+		writeError(rw, errorStatus(err), err)
+		return
+	}
+	writeJSON(rw, http.StatusOK, solutionResponse{
+		ID:       id,
+		Solution: mcla.ExpandSolution(sol, data),
+		Rendered: mcla.RenderSolution(sol, data, format),
+	})
+}
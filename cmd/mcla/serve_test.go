@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GlobeMC/mcla"
+)
+
+type testErrorDB struct{}
+
+func (testErrorDB) ForEachErrors(callback func(*mcla.ErrorDesc) error) error {
+	return callback(&mcla.ErrorDesc{
+		ID:        1,
+		Error:     "java.lang.IllegalStateException",
+		Message:   "Bad state *",
+		Solutions: []int{1},
+	})
+}
+
+func (testErrorDB) GetSolution(id int) (*mcla.SolutionDesc, error) {
+	if id != 1 {
+		return nil, nil
+	}
+	return &mcla.SolutionDesc{Description: "Update ${mod}"}, nil
+}
+
+const (
+	testServeLog = "[00:00:00] [main/INFO]: Starting\n" +
+		"java.lang.IllegalStateException: Bad state of mod\n" +
+		"\tat com.example.mod.Mob.tick(Mob.java:1)\n" +
+		"Caused by: java.lang.NullPointerException: null\n" +
+		"\tat com.example.mod.Mob.init(Mob.java:2)\n"
+	testServeCrashReport = "---- Minecraft Crash Report ----\n" +
+		"Description: Ticking entity\n" +
+		"\n" +
+		"java.lang.IllegalStateException: Bad state of mod\n" +
+		"\tat com.example.mod.Mob.tick(Mob.java:1)\n"
+)
+
+func newTestServer(t *testing.T, maxSize int64, timeout time.Duration) *httptest.Server {
+	a := mcla.NewAnalyzer(testErrorDB{})
+	a.ResolveSolutions = true
+	server := httptest.NewServer(newServeHandler(a, maxSize, timeout))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func decodeResponse(t *testing.T, res *http.Response, status int, v any) {
+	t.Helper()
+	defer res.Body.Close()
+	if res.StatusCode != status {
+		buf, _ := io.ReadAll(res.Body)
+		t.Fatalf("Expect status %d, got %d: %s", status, res.StatusCode, buf)
+	}
+	if v != nil {
+		if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+			t.Fatalf("Cannot decode response: %v", err)
+		}
+	}
+}
+
+func TestServeErrors(t *testing.T) {
+	server := newTestServer(t, 0, 0)
+	res, err := http.Post(server.URL+"/api/errors", "text/plain", strings.NewReader(testServeLog))
+	if err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	var errs []*mcla.JavaError
+	decodeResponse(t, res, http.StatusOK, &errs)
+	if len(errs) != 1 || errs[0].CausedBy == nil || errs[0].LineNo != 2 {
+		t.Errorf("Expect the error at line 2 with its cause, got %v", errs)
+	}
+}
+
+func TestServeCrashReportMultipart(t *testing.T) {
+	server := newTestServer(t, 0, 0)
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("comment", "crashed on startup")
+	fw, _ := mw.CreateFormFile("file", "crash-2024-01-01.txt")
+	io.WriteString(fw, testServeCrashReport)
+	mw.Close()
+	res, err := http.Post(server.URL+"/api/crashreport", mw.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	var report mcla.CrashReport
+	decodeResponse(t, res, http.StatusOK, &report)
+	if report.Description != "Ticking entity" || report.Error == nil {
+		t.Errorf("Unexpected report %+v", report)
+	}
+}
+
+func TestServeAnalyze(t *testing.T) {
+	server := newTestServer(t, 0, 0)
+	res, err := http.Post(server.URL+"/api/analyze", "text/plain", strings.NewReader(testServeLog))
+	if err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	defer res.Body.Close()
+	if ct := res.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Expect NDJSON, got %q", ct)
+	}
+	var results []*mcla.ErrorResult
+	sc := bufio.NewScanner(res.Body)
+	for sc.Scan() {
+		var r mcla.ErrorResult
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			t.Fatalf("Cannot decode line %q: %v", sc.Text(), err)
+		}
+		results = append(results, &r)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expect a result for the error and its cause, got %d", len(results))
+	}
+	for _, r := range results {
+		if r.Error.Class == "java.lang.IllegalStateException" && (len(r.Solutions) != 1 || r.Solutions[0].ID != 1) {
+			t.Errorf("Expect the solution is resolved, got %v", r.Solutions)
+		}
+	}
+}
+
+func TestServeAnalyzeSSE(t *testing.T) {
+	server := newTestServer(t, 0, 0)
+	res, err := http.Post(server.URL+"/api/analyze?stream=sse", "text/plain", strings.NewReader(testServeLog))
+	if err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	defer res.Body.Close()
+	buf, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if n := strings.Count((string)(buf), "event: result\n"); n != 2 {
+		t.Errorf("Expect 2 result events, got %d", n)
+	}
+	if !strings.HasSuffix((string)(buf), "event: end\ndata: {}\n\n") {
+		t.Errorf("Expect the stream ends with an end event, got %q", buf)
+	}
+}
+
+func TestServeSolution(t *testing.T) {
+	server := newTestServer(t, 0, 0)
+	res, err := http.Get(server.URL + "/api/solution/1?mod=create")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	var sol solutionResponse
+	decodeResponse(t, res, http.StatusOK, &sol)
+	if sol.ID != 1 || sol.Rendered != "Update create" {
+		t.Errorf("Unexpected solution %+v", sol)
+	}
+
+	cases := []struct {
+		path   string
+		status int
+	}{
+		{"/api/solution/2", http.StatusNotFound},
+		{"/api/solution/abc", http.StatusBadRequest},
+		{"/api/solution/1?format=pdf", http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		res, err := http.Get(server.URL + c.path)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		decodeResponse(t, res, c.status, nil)
+	}
+}
+
+// countingErrorDB counts the solutions got from the database
+type countingErrorDB struct {
+	testErrorDB
+	solutions atomic.Int32
+}
+
+func (db *countingErrorDB) GetSolution(id int) (*mcla.SolutionDesc, error) {
+	db.solutions.Add(1)
+	return db.testErrorDB.GetSolution(id)
+}
+
+func TestServeSolutionCached(t *testing.T) {
+	db := new(countingErrorDB)
+	server := httptest.NewServer(newServeHandler(mcla.NewAnalyzer(db), 0, 0))
+	defer server.Close()
+	for range 2 {
+		res, err := http.Get(server.URL + "/api/solution/1")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		decodeResponse(t, res, http.StatusOK, nil)
+	}
+	if n := db.solutions.Load(); n != 1 {
+		t.Errorf("Expect the solution is cached by the analyzer, got %d database requests", n)
+	}
+}
+
+func TestServeMaxSize(t *testing.T) {
+	server := newTestServer(t, 16, 0)
+	res, err := http.Post(server.URL+"/api/errors", "text/plain", strings.NewReader(testServeLog))
+	if err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	decodeResponse(t, res, http.StatusRequestEntityTooLarge, nil)
+}
+
+// slowReader sends a log line every tick, and never ends
+type slowReader struct {
+	tick time.Duration
+}
+
+func (r slowReader) Read(buf []byte) (int, error) {
+	time.Sleep(r.tick)
+	return copy(buf, "[00:00:00] [main/INFO]: Loading\n"), nil
+}
+
+func TestServeTimeout(t *testing.T) {
+	handler := newServeHandler(mcla.NewAnalyzer(testErrorDB{}), 0, 100*time.Millisecond)
+	for _, path := range []string{"/api/errors", "/api/crashreport", "/api/analyze"} {
+		req := httptest.NewRequest("POST", path, slowReader{5 * time.Millisecond})
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusGatewayTimeout {
+			t.Errorf("Expect %s is timed out, got %d: %s", path, rec.Code, rec.Body)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/GlobeMC/mcla"
+)
+
+// solutionCommand previews a solution with the given placeholder values
+func solutionCommand(args []string) {
+	flags := flag.NewFlagSet("solution", flag.ExitOnError)
+	formatStr := flags.String("format", "text", "")
+	flags.Parse(args)
+	format, err := mcla.ParseRenderFormat(*formatStr)
+	if err != nil {
+		printf("[ERROR]: %v", err)
+		os.Exit(exitUsage)
+	}
+	if flags.NArg() == 0 {
+		printf("[ERROR]: Must give the solution ID as the argument")
+		os.Exit(exitUsage)
+	}
+	id, err := strconv.Atoi(flags.Arg(0))
+	if err != nil {
+		printf("[ERROR]: Solution ID %q is not a number", flags.Arg(0))
+		os.Exit(exitUsage)
+	}
+	data := make(map[string]any)
+	for _, arg := range flags.Args()[1:] {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			printf("[ERROR]: Placeholder value %q is not in <key>=<value> format", arg)
+			os.Exit(exitUsage)
+		}
+		data[key] = value
+	}
+	sol, err := defaultAnalyzer.GetLocalizedSolution(id, locale)
+	if err != nil {
+		printf("Error when getting solution %d: %v", id, err)
+		os.Exit(exitFailure)
+	}
+	fmt.Println(mcla.RenderSolution(sol, data, format))
+}
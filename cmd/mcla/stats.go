@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/GlobeMC/mcla"
+)
+
+// analyzeFiles analyzes the files with the workers in parallel, and calls fn for each result
+func analyzeFiles(files []*mcla.InstanceFile, workers int, fn func(*mcla.InstanceFileResult)) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	queue := make(chan *mcla.InstanceFile)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range queue {
+				fn(defaultAnalyzer.AnalyzeFile(context.Background(), file))
+			}
+		}()
+	}
+	for _, file := range files {
+		queue <- file
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// statsCommand analyzes all crash reports and logs in a directory, and reports the most frequent ones
+func statsCommand(args []string) {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	top := flags.Int("top", 20, "")
+	asCSV := flags.Bool("csv", false, "")
+	workers := flags.Int("j", 0, "")
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		printf("[ERROR]: Must give the directory as the argument")
+		os.Exit(exitUsage)
+	}
+	dir := flags.Arg(0)
+	files, err := mcla.FindBatchFiles(os.DirFS(dir))
+	if err != nil {
+		printf("Error when reading directory %q: %v", dir, err)
+		os.Exit(exitFailure)
+	}
+	if len(files) == 0 {
+		printf("No any log was found in %q", dir)
+		return
+	}
+
+	collector := mcla.NewStatsCollector()
+	analyzeFiles(files, *workers, func(res *mcla.InstanceFileResult) {
+		if res.Failure != "" {
+			printf("[WARN]: Cannot analyze %q: %s", res.Name, res.Failure)
+		}
+		collector.Add(res)
+	})
+	stats := collector.Stats(*top)
+
+	switch {
+	case *asCSV:
+		err = writeStatsCSV(os.Stdout, stats)
+	case output == outputJSON || output == outputNDJSON:
+		err = newJSONEncoder().Encode(stats)
+	default:
+		err = writeStatsText(os.Stdout, stats)
+	}
+	if err != nil {
+		printf("Error when printing stats: %v", err)
+		os.Exit(exitFailure)
+	}
+}
+
+// writeStatsCSV writes the stats in the columns section, key, count and detail
+func writeStatsCSV(w io.Writer, stats *mcla.Stats) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"section", "key", "count", "detail"})
+	for _, v := range []struct {
+		key   string
+		count int
+	}{
+		{"files", stats.Files},
+		{"crashReports", stats.CrashReports},
+		{"jvmCrashes", stats.JVMCrashes},
+		{"logs", stats.Logs},
+		{"failed", stats.Failed},
+		{"errors", stats.Errors},
+	} {
+		cw.Write([]string{"total", v.key, strconv.Itoa(v.count), ""})
+	}
+	for _, fp := range stats.Fingerprints {
+		cw.Write([]string{"fingerprint", fp.Fingerprint, strconv.Itoa(fp.Count), strings.Join(fp.Files, ";")})
+	}
+	for _, sol := range stats.Solutions {
+		cw.Write([]string{"solution", strconv.Itoa(sol.ID), strconv.Itoa(sol.Count), sol.Description})
+	}
+	for _, section := range []struct {
+		name    string
+		entries []mcla.CountEntry
+	}{
+		{"mod", stats.SuspectedMods},
+		{"minecraft", stats.MinecraftVersions},
+		{"loader", stats.Loaders},
+		{"java", stats.JavaVersions},
+	} {
+		for _, e := range section.entries {
+			cw.Write([]string{section.name, e.Key, strconv.Itoa(e.Count), ""})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeStatsText(w io.Writer, stats *mcla.Stats) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Analyzed %d file(s): %d crash report(s), %d JVM crash(es), %d log(s), %d failed\n",
+		stats.Files, stats.CrashReports, stats.JVMCrashes, stats.Logs, stats.Failed)
+	fmt.Fprintf(tw, "Found %d error(s)\n", stats.Errors)
+	if len(stats.Fingerprints) > 0 {
+		fmt.Fprintf(tw, "\nTop errors:\n")
+		for _, fp := range stats.Fingerprints {
+			fmt.Fprintf(tw, "  %d\t%s\n", fp.Count, fp.Fingerprint)
+		}
+	}
+	if len(stats.Solutions) > 0 {
+		fmt.Fprintf(tw, "\nTop solutions:\n")
+		for _, sol := range stats.Solutions {
+			fmt.Fprintf(tw, "  %d\t#%d\t%s\n", sol.Count, sol.ID, sol.Description)
+		}
+	}
+	for _, section := range []struct {
+		title   string
+		entries []mcla.CountEntry
+	}{
+		{"Suspected mods", stats.SuspectedMods},
+		{"Minecraft versions", stats.MinecraftVersions},
+		{"Loaders", stats.Loaders},
+		{"Java versions", stats.JavaVersions},
+	} {
+		if len(section.entries) == 0 {
+			continue
+		}
+		fmt.Fprintf(tw, "\n%s:\n", section.title)
+		for _, e := range section.entries {
+			fmt.Fprintf(tw, "  %d\t%s\n", e.Count, e.Key)
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/GlobeMC/mcla"
+)
+
+const defaultWatchInterval = 500 * time.Millisecond
+
+// watchFlushIntervals is how many intervals without new content before the last error is flushed
+const watchFlushIntervals = 3
+
+// watchCommand follows a growing log and analyzes the new errors once they are written
+func watchCommand(args []string) {
+	flags := flag.NewFlagSet("watch", flag.ExitOnError)
+	interval := flags.Duration("interval", defaultWatchInterval, "")
+	all := flags.Bool("all", false, "")
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		printf("[ERROR]: Must give the log filename as the argument")
+		os.Exit(exitUsage)
+	}
+	if output == outputHTML {
+		printf("[ERROR]: The html format cannot be used with watch")
+		os.Exit(exitUsage)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	// the database requests in the backoff are not waited when interrupted
+	defaultErrDB.Context = ctx
+	if err := watchLog(ctx, flags.Arg(0), *interval, *all); err != nil && ctx.Err() == nil {
+		printf("[ERROR]: %v", err)
+		os.Exit(exitFailure)
+	}
+}
+
+// watchLog analyzes every generation of the log until ctx is done.
+// When the game restarts, latest.log is archived and a new one is created,
+// which will be analyzed as a new stream so the line numbers start from 1 again
+func watchLog(ctx context.Context, path string, interval time.Duration, all bool) error {
+	printer := newResultPrinter(path, false)
+	for first := true; ctx.Err() == nil; first = false {
+		tail, err := openLogTail(ctx, path, interval)
+		if err != nil {
+			return err
+		}
+		// the encoding is detected once from the existing content, since the tail waits for the new content
+		lines, enc, err := countLines(tail.file)
+		if err != nil {
+			tail.Close()
+			return err
+		}
+		skipLines := 0
+		if first && !all {
+			// the existing lines are still analyzed for the context, but not printed
+			skipLines = lines
+		}
+		printf("[INFO]: Watching %q", path)
+		decoded, err := mcla.NewDecodedReader(tail, enc)
+		if err != nil {
+			tail.Close()
+			return err
+		}
+		flusher := newIdleFlusher(decoded, interval*watchFlushIntervals)
+		// the flushed content is decoded, so the analyzer will not wait for a sample to detect the encoding
+		result, sctx := defaultAnalyzer.DoLogStream(ctx, &mcla.DecodedReader{Reader: flusher, Encoding: decoded.Encoding})
+	LOOP_RES:
+		for {
+			select {
+			case res := <-result:
+				if res == nil { // rotated
+					break LOOP_RES
+				}
+				flusher.correctLineNo(res.Error)
+				if res.Error.LineNo <= skipLines {
+					continue
+				}
+				res.File = path
+				if err = printer.Add(res); err != nil {
+					flusher.Close()
+					tail.Close()
+					return err
+				}
+			case <-sctx.Done():
+				flusher.Close()
+				tail.Close()
+				return context.Cause(sctx)
+			}
+		}
+		flusher.Close()
+		tail.Close()
+		if err = printer.Done(); err != nil {
+			return err
+		}
+		if ctx.Err() == nil {
+			printf("[INFO]: %q was rotated", path)
+		}
+	}
+	return nil
+}
+
+// countLines counts the lines of the decoded content, so it matches the line numbers of the results,
+// enc is the encoding of the content, which is detected after the whole content is read
+func countLines(fd *os.File) (n int, enc string, err error) {
+	r, err := mcla.NewDecodedReader(io.NewSectionReader(fd, 0, 1<<62), encodingFlag)
+	if err != nil {
+		return
+	}
+	var buf [32 * 1024]byte
+	for {
+		var m int
+		m, err = r.Read(buf[:])
+		n += bytes.Count(buf[:m], []byte{'\n'})
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return n, r.Encoding, err
+		}
+	}
+}
+
+// logTail reads a file like `tail -F`, it waits for the new content at EOF,
+// and returns io.EOF once the file is replaced, truncated or ctx is done
+type logTail struct {
+	ctx      context.Context
+	path     string
+	interval time.Duration
+	file     *os.File
+	info     fs.FileInfo
+	offset   int64
+}
+
+var _ io.ReadCloser = (*logTail)(nil)
+
+// openLogTail waits until the file exists
+func openLogTail(ctx context.Context, path string, interval time.Duration) (t *logTail, err error) {
+	for {
+		fd, err := os.Open(path)
+		if err == nil {
+			info, err := fd.Stat()
+			if err != nil {
+				fd.Close()
+				return nil, err
+			}
+			return &logTail{
+				ctx:      ctx,
+				path:     path,
+				interval: interval,
+				file:     fd,
+				info:     info,
+			}, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		}
+	}
+}
+
+func (t *logTail) Read(buf []byte) (n int, err error) {
+	for {
+		n, err = t.file.Read(buf)
+		t.offset += (int64)(n)
+		if n > 0 || err != io.EOF {
+			return
+		}
+		if t.rotated() {
+			return 0, io.EOF
+		}
+		select {
+		case <-time.After(t.interval):
+		case <-t.ctx.Done():
+			return 0, io.EOF
+		}
+	}
+}
+
+// rotated checks if the path is pointing to another file now.
+// It is only called at EOF, so the remaining content of the old file has been read
+func (t *logTail) rotated() bool {
+	info, err := os.Stat(t.path)
+	if err != nil {
+		return !errors.Is(err, fs.ErrNotExist) // the new file may not be created yet
+	}
+	return !os.SameFile(t.info, info) || info.Size() < t.offset
+}
+
+func (t *logTail) Close() error {
+	return t.file.Close()
+}
+
+// idleFlusher passes the log through, and adds an empty line once no new content arrives within the delay,
+// so the error at the end of the log is completed without waiting for the next log line.
+// The added lines are recorded to correct the line numbers of the results
+type idleFlusher struct {
+	chunks chan []byte
+	done   chan struct{}
+	err    error // set before chunks is closed
+	delay  time.Duration
+
+	buf     []byte
+	pending bool // new content was passed after the last flush
+	lines   int  // the passed lines, including the added ones
+	partial bool // the last line is not completed
+	blank   bool // the last completed line is empty
+
+	mux   sync.Mutex
+	added []int // the line numbers of the added lines
+
+	corrected map[*mcla.JavaError]struct{} // the causes whose line numbers are converted
+}
+
+var _ io.ReadCloser = (*idleFlusher)(nil)
+
+func newIdleFlusher(r io.Reader, delay time.Duration) (f *idleFlusher) {
+	f = &idleFlusher{
+		chunks: make(chan []byte),
+		done:   make(chan struct{}),
+		delay:  delay,
+		blank:  true,
+
+		corrected: make(map[*mcla.JavaError]struct{}),
+	}
+	go func() {
+		defer close(f.chunks)
+		for {
+			buf := make([]byte, 32*1024)
+			n, err := r.Read(buf)
+			if n > 0 {
+				select {
+				case f.chunks <- buf[:n]:
+				case <-f.done:
+					return
+				}
+			}
+			if err != nil {
+				f.err = err
+				return
+			}
+		}
+	}()
+	return
+}
+
+func (f *idleFlusher) Read(buf []byte) (n int, err error) {
+	for len(f.buf) == 0 {
+		var (
+			timer *time.Timer
+			idle  <-chan time.Time
+		)
+		if f.pending && !f.partial && !f.blank {
+			timer = time.NewTimer(f.delay)
+			idle = timer.C
+		}
+		var (
+			chunk   []byte
+			ok      = true
+			flushed bool
+		)
+		select {
+		case chunk, ok = <-f.chunks:
+		case <-idle:
+			flushed = true
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		switch {
+		case flushed:
+			f.pending = false
+			f.buf = []byte{'\n'}
+			f.mux.Lock()
+			f.added = append(f.added, f.lines+1)
+			f.mux.Unlock()
+		case !ok:
+			return 0, f.err
+		default:
+			f.buf = chunk
+			f.pending = true
+		}
+	}
+	n = copy(buf, f.buf)
+	for _, b := range f.buf[:n] {
+		if b == '\n' {
+			f.lines++
+			f.blank = !f.partial
+			f.partial = false
+		} else if b != '\r' {
+			f.partial = true
+		}
+	}
+	f.buf = f.buf[n:]
+	return
+}
+
+// lineNo converts the line number of the flushed stream to the line number of the log
+func (f *idleFlusher) lineNo(n int) int {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	i, _ := slices.BinarySearch(f.added, n)
+	return n - i
+}
+
+// correctLineNo converts the line numbers of the error and its causes.
+// The causes are also sent as their own results after the root, so they are recorded to not be converted twice
+func (f *idleFlusher) correctLineNo(jerr *mcla.JavaError) {
+	if _, ok := f.corrected[jerr]; ok {
+		delete(f.corrected, jerr)
+		return
+	}
+	jerr.LineNo = f.lineNo(jerr.LineNo)
+	for cause := jerr.CausedBy; cause != nil; cause = cause.CausedBy {
+		cause.LineNo = f.lineNo(cause.LineNo)
+		f.corrected[cause] = struct{}{}
+	}
+}
+
+func (f *idleFlusher) Close() error {
+	select {
+	case <-f.done:
+	default:
+		close(f.done)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/GlobeMC/mcla"
+)
+
+func TestIdleFlusher(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	flusher := newIdleFlusher(pr, 20*time.Millisecond)
+	defer flusher.Close()
+
+	a := mcla.NewAnalyzer(testErrorDB{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	result, _ := a.DoLogStream(ctx, &mcla.DecodedReader{Reader: flusher, Encoding: mcla.EncodingUTF8})
+
+	go io.WriteString(pw, testServeLog)
+	next := func() *mcla.ErrorResult {
+		t.Helper()
+		select {
+		case res := <-result:
+			flusher.correctLineNo(res.Error)
+			return res
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expect the last error is flushed while the log is idle")
+		}
+		return nil
+	}
+	for _, lineNo := range []int{2, 4} {
+		if res := next(); res.Error.LineNo != lineNo {
+			t.Errorf("Expect the error at line %d, got %d", lineNo, res.Error.LineNo)
+		}
+	}
+
+	// the added line is not counted, in the causes either
+	go io.WriteString(pw, "java.lang.IllegalStateException: Bad state again\n\tat com.example.mod.Mob.tick(Mob.java:1)\n"+
+		"Caused by: java.lang.NullPointerException: null\n\tat com.example.mod.Mob.init(Mob.java:2)\n")
+	root := next()
+	if root.Error.LineNo != 6 || root.Error.CausedBy == nil || root.Error.CausedBy.LineNo != 8 {
+		t.Errorf("Expect the error at line 6 caused by line 8, got %+v", root.Error)
+	}
+	if cause := next(); cause.Error.LineNo != 8 {
+		t.Errorf("Expect the cause at line 8, got %d", cause.Error.LineNo)
+	}
+}
+
+func TestCountLinesUTF16(t *testing.T) {
+	fd, err := os.CreateTemp(t.TempDir(), "latest-*.log")
+	if err != nil {
+		t.Fatalf("CreateTemp: %v", err)
+	}
+	defer fd.Close()
+	// "a\nb\n" in UTF-16LE with BOM, the second byte of every character is zero
+	if _, err = fd.Write([]byte{0xff, 0xfe, 'a', 0, '\n', 0, 'b', 0, '\n', 0, 0x0a, 0x01}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	n, enc, err := countLines(fd)
+	if err != nil {
+		t.Fatalf("countLines: %v", err)
+	}
+	if n != 2 || enc != mcla.EncodingUTF16LE {
+		t.Errorf("Expect 2 lines in %s, got %d in %s", mcla.EncodingUTF16LE, n, enc)
+	}
+}
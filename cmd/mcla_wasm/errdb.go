@@ -1,9 +1,8 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"io"
-	"net/url"
 	"strings"
 	"sync"
 	"syscall/js"
@@ -12,19 +11,12 @@ import (
 	"github.com/GlobeMC/mcla/ghdb"
 )
 
-type HTTPStatusErr struct {
-	URL        string
-	StatusCode int
+var ghRepoMirrors = []string{
+	"https://raw.githubusercontent.com/kmcsr/mcla-db-dev/main",
+	"https://cdn.jsdelivr.net/gh/kmcsr/mcla-db-dev@main",
+	"https://fastly.jsdelivr.net/gh/kmcsr/mcla-db-dev@main",
 }
 
-func (e *HTTPStatusErr) Error() string {
-	return fmt.Sprintf("HTTP status code error: %d when getting %q", e.StatusCode, e.URL)
-}
-
-var ghRepoPrefix = "https://raw.githubusercontent.com/kmcsr/mcla-db-dev/main"
-
-// TODO: use https://developer.mozilla.org/en-US/docs/Web/API/IDBFactory
-
 type JsStorageCache struct {
 	storage js.Value
 	prefix  string
@@ -114,25 +106,59 @@ func (s *JsStorageCache) GetOrSet(key string, setter func() string) string {
 	return v
 }
 
-const appStorageKeyPrefix = "com.github.kmcsr.mcla."
+const (
+	appStorageKeyPrefix = "com.github.kmcsr.mcla."
+	appIDBName          = "com.github.kmcsr.mcla"
+	appIDBCacheStore    = "cache"
+)
 
-var defaultErrDB = &ghdb.ErrDB{
-	Cache: NewJsStorageCache(localStorage, appStorageKeyPrefix),
-	Fetch: func(path string) (io.ReadCloser, error) {
-		path, err := url.JoinPath(ghRepoPrefix, path)
-		if err != nil {
-			return nil, err
-		}
-		res, err := fetch(path)
-		if err != nil {
-			return nil, err
-		}
-		if res.StatusCode != 200 {
-			res.Body.Close()
-			return nil, &HTTPStatusErr{res.Url, res.StatusCode}
+// openDefaultCache prefers IndexedDB, and fallback to localStorage when it is unavailable
+func openDefaultCache() ghdb.Cache {
+	cache, err := OpenIDBCache(appIDBName, appIDBCacheStore)
+	if err != nil {
+		fmt.Printf("Cannot open IndexedDB cache, fallback to localStorage: %v\n", err)
+		if !localStorage.Truthy() {
+			return ghdb.NewInMemoryCache()
 		}
-		return res.Body, nil
-	},
+		return NewJsStorageCache(localStorage, appStorageKeyPrefix)
+	}
+	if n := migrateStorageCache(localStorage, appStorageKeyPrefix, cache); n > 0 {
+		fmt.Printf("Migrated %d cache entries from localStorage to IndexedDB\n", n)
+	}
+	return cache
+}
+
+func fetchDo(ctx context.Context, url string, req *ghdb.FetchRequest) (*ghdb.FetchResponse, error) {
+	header := make(Map, 2)
+	if req.ETag != "" {
+		header["If-None-Match"] = req.ETag
+	}
+	if req.LastModified != "" {
+		header["If-Modified-Since"] = req.LastModified
+	}
+	res, err := fetchContext(ctx, url, Map{"headers": header})
+	if err != nil {
+		return nil, err
+	}
+	switch res.StatusCode {
+	case 200:
+		return &ghdb.FetchResponse{
+			Body:         res.Body,
+			ETag:         res.Header.Get("ETag"),
+			LastModified: res.Header.Get("Last-Modified"),
+		}, nil
+	case 304:
+		res.Body.Close()
+		return &ghdb.FetchResponse{NotModified: true}, nil
+	}
+	res.Body.Close()
+	return nil, &ghdb.HTTPStatusErr{URL: res.Url, StatusCode: res.StatusCode}
+}
+
+var ghdbMirrors = ghdb.NewMirrorFetcher(fetchDo, ghRepoMirrors...)
+
+var defaultErrDB = &ghdb.ErrDB{
+	Fetcher: ghdbMirrors,
 }
 
 var defaultAnalyzer = mcla.NewAnalyzer(defaultErrDB)
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"syscall/js"
+
+	"github.com/GlobeMC/mcla/ghdb"
+)
+
+var ErrIDBUnavailable = errors.New("IndexedDB is not available")
+
+// IDBCache stores the cache entries in an IndexedDB object store,
+// so it is not limited by the localStorage quota and won't block the main thread
+type IDBCache struct {
+	db    js.Value // IDBDatabase
+	store string
+
+	workMux sync.RWMutex
+	working map[string]chan struct{}
+}
+
+var _ ghdb.Cache = &IDBCache{}
+
+const idbVersion = 1
+
+func OpenIDBCache(name string, store string) (c *IDBCache, err error) {
+	if !indexedDB.Truthy() {
+		return nil, ErrIDBUnavailable
+	}
+	req := indexedDB.Call("open", name, idbVersion)
+	upgrade := js.FuncOf(func(_ js.Value, _ []js.Value) (res any) {
+		db := req.Get("result")
+		if !db.Get("objectStoreNames").Call("contains", store).Bool() {
+			db.Call("createObjectStore", store)
+		}
+		return
+	})
+	defer upgrade.Release()
+	req.Set("onupgradeneeded", upgrade)
+	db, err := awaitIDBRequest(req)
+	if err != nil {
+		return
+	}
+	return &IDBCache{
+		db:      db,
+		store:   store,
+		working: make(map[string]chan struct{}, 32),
+	}, nil
+}
+
+// idbRequestPromise wraps an IDBRequest as a Promise
+func idbRequestPromise(req js.Value) js.Value {
+	var onsuccess, onerror js.Func
+	executor := js.FuncOf(func(_ js.Value, args []js.Value) (res any) {
+		resolve, reject := args[0], args[1]
+		onsuccess = js.FuncOf(func(_ js.Value, _ []js.Value) (res any) {
+			onsuccess.Release()
+			onerror.Release()
+			resolve.Invoke(req.Get("result"))
+			return
+		})
+		onerror = js.FuncOf(func(_ js.Value, _ []js.Value) (res any) {
+			onsuccess.Release()
+			onerror.Release()
+			reject.Invoke(req.Get("error"))
+			return
+		})
+		req.Set("onsuccess", onsuccess)
+		req.Set("onerror", onerror)
+		return
+	})
+	promise := Promise.New(executor)
+	executor.Release()
+	return promise
+}
+
+func awaitIDBRequest(req js.Value) (res js.Value, err error) {
+	return awaitPromise(idbRequestPromise(req))
+}
+
+func (c *IDBCache) objectStore(mode string) js.Value {
+	return c.db.Call("transaction", c.store, mode).Call("objectStore", c.store)
+}
+
+// logIDBError logs the errors of the ghdb.Cache methods, since the interface cannot return them
+func logIDBError(op string, err error) {
+	if err != nil {
+		fmt.Printf("IndexedDB cache %s failed: %v\n", op, err)
+	}
+}
+
+func (c *IDBCache) Clear() {
+	logIDBError("clear", c.ClearErr())
+}
+
+// ClearErr is Clear, but returns the error
+func (c *IDBCache) ClearErr() (err error) {
+	_, err = awaitIDBRequest(c.objectStore("readwrite").Call("clear"))
+	return
+}
+
+func (c *IDBCache) Get(key string) string {
+	c.workMux.RLock()
+	ch := c.working[key]
+	c.workMux.RUnlock()
+	if ch != nil {
+		<-ch
+	}
+	res, err := awaitIDBRequest(c.objectStore("readonly").Call("get", key))
+	if err != nil || res.Type() != js.TypeString {
+		return ""
+	}
+	return res.String()
+}
+
+func (c *IDBCache) Set(key string, value string) {
+	logIDBError("put", c.SetErr(key, value))
+}
+
+// SetErr is Set, but returns the error, e.g. when the storage quota is exceeded
+func (c *IDBCache) SetErr(key string, value string) (err error) {
+	_, err = awaitIDBRequest(c.objectStore("readwrite").Call("put", value, key))
+	return
+}
+
+func (c *IDBCache) Remove(key string) {
+	logIDBError("delete", c.RemoveErr(key))
+}
+
+// RemoveErr is Remove, but returns the error
+func (c *IDBCache) RemoveErr(key string) (err error) {
+	_, err = awaitIDBRequest(c.objectStore("readwrite").Call("delete", key))
+	return
+}
+
+func (c *IDBCache) GetOrSet(key string, setter func() string) string {
+	v := c.Get(key)
+	if v == "" {
+		c.workMux.Lock()
+		if ch := c.working[key]; ch != nil {
+			c.workMux.Unlock()
+			return c.Get(key)
+		}
+		done := make(chan struct{}, 0)
+		c.working[key] = done
+		c.workMux.Unlock()
+
+		v = setter()
+		c.Set(key, v)
+		close(done)
+		c.workMux.Lock()
+		delete(c.working, key)
+		c.workMux.Unlock()
+	}
+	return v
+}
+
+// migrateStorageCache moves the entries cached by the old JsStorageCache into dst,
+// the entries which cannot be stored are kept in the storage
+func migrateStorageCache(storage js.Value, prefix string, dst *IDBCache) (n int) {
+	if !storage.Truthy() {
+		return
+	}
+	leng := storage.Get("length").Int()
+	keys := make([]string, 0, 16)
+	for i := 0; i < leng; i++ {
+		key := storage.Call("key", i).String()
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range keys {
+		if value := storage.Call("getItem", key); value.Type() == js.TypeString {
+			if err := dst.SetErr(key[len(prefix):], value.String()); err != nil {
+				logIDBError("migration", err)
+				continue
+			}
+			n++
+		}
+		storage.Call("removeItem", key)
+	}
+	return
+}
@@ -10,6 +10,7 @@ var (
 	Object                      = global.Get("Object")
 	Reflect                     = global.Get("Reflect")
 	Symbol                      = global.Get("Symbol")
+	JSON                        = global.Get("JSON")
 	Promise                     = global.Get("Promise")
 	Array                       = global.Get("Array")
 	Uint8Array                  = global.Get("Uint8Array")
@@ -20,7 +21,9 @@ var (
 	jsFetch = global.Get("fetch")
 	// API
 	console        = global.Get("console")
+	navigator      = global.Get("navigator")
 	caches         = global.Get("caches")
 	sessionStorage = global.Get("sessionStorage")
 	localStorage   = global.Get("localStorage")
+	indexedDB      = global.Get("indexedDB")
 )
@@ -2,9 +2,12 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"sync"
 	"syscall/js"
+	"time"
 
 	. "github.com/GlobeMC/mcla"
 )
@@ -23,6 +26,9 @@ func getAPI() (m Map) {
 		"parseCrashReport": asyncFuncOf(func(_ js.Value, args []js.Value) (res any, err error) {
 			return parseCrashReport(args)
 		}),
+		"analyzeCrashReport": asyncFuncOf(func(_ js.Value, args []js.Value) (res any, err error) {
+			return analyzeCrashReport(args)
+		}),
 		"parseLogErrors": asyncFuncOf(func(_ js.Value, args []js.Value) (res any, err error) {
 			return parseLogErrors(args)
 		}),
@@ -32,13 +38,36 @@ func getAPI() (m Map) {
 		"analyzeLogErrorsIter": asyncFuncOf(func(_ js.Value, args []js.Value) (res any, err error) {
 			return analyzeLogErrorsIter(args)
 		}),
+		"renderSolution": asyncFuncOf(func(_ js.Value, args []js.Value) (res any, err error) {
+			return renderSolution(args)
+		}),
+		"searchSolutions": asyncFuncOf(func(_ js.Value, args []js.Value) (res any, err error) {
+			return searchSolutions(args)
+		}),
+		"setAnalyzerOptions": js.FuncOf(func(_ js.Value, args []js.Value) (res any) {
+			setAnalyzerOptions(args[0])
+			return
+		}),
 		"setGhDbPrefix": js.FuncOf(func(_ js.Value, args []js.Value) (res any) {
+			// accepts either a prefix or an array of mirror prefixes
 			prefix := args[0]
-			prefixStr := prefix.String()
-			fmt.Printf("Set database as %q\n", prefixStr)
-			ghRepoPrefix = prefixStr
+			var prefixes []string
+			if prefix.InstanceOf(Array) {
+				leng := prefix.Length()
+				prefixes = make([]string, leng)
+				for i := 0; i < leng; i++ {
+					prefixes[i] = prefix.Index(i).String()
+				}
+			} else {
+				prefixes = []string{prefix.String()}
+			}
+			fmt.Printf("Set database as %q\n", prefixes)
+			ghdbMirrors.SetMirrors(prefixes)
 			return
 		}),
+		"getGhDbMirrors": js.FuncOf(func(_ js.Value, _ []js.Value) (res any) {
+			return asJsValue(ghdbMirrors.Mirrors())
+		}),
 	}
 }
 
@@ -48,6 +77,11 @@ Under GNU AFFERO GENERAL PUBLIC LICENSE v3
 `
 
 func main() {
+	defaultErrDB.Cache = openDefaultCache()
+	defaultAnalyzer.ResolveSolutions = true
+	if navigator.Truthy() {
+		defaultAnalyzer.Locale = navigator.Get("language").String()
+	}
 	defaultErrDB.RefreshCache()
 
 	api := getAPI()
@@ -80,6 +114,16 @@ func parseCrashReport(args []js.Value) (report *CrashReport, err error) {
 	return
 }
 
+// analyzeCrashReport(report: string | Uint8Array | ReadableStream): Promise<CrashReportResult | null>
+// The solutions failed to fetch don't reject the promise, they are listed in result.unresolvedSolutions
+func analyzeCrashReport(args []js.Value) (result *CrashReportResult, err error) {
+	report, err := parseCrashReport(args)
+	if err != nil || report == nil {
+		return
+	}
+	return defaultAnalyzer.AnalyzeCrashReport(report)
+}
+
 func parseLogErrors(args []js.Value) (errs []*JavaError, err error) {
 	value := args[0]
 	r, err := wrapJsValueAsReader(value)
@@ -100,6 +144,9 @@ func analyzeLogErrors(args []js.Value) (result []*ErrorResult, err error) {
 	for {
 		select {
 		case res := <-resCh:
+			if res == nil {
+				return
+			}
 			result = append(result, res)
 		case <-ctx.Done():
 			return nil, context.Cause(ctx)
@@ -118,3 +165,93 @@ func analyzeLogErrorsIter(args []js.Value) (iterator js.Value, err error) {
 	iterator = NewChannelIteratorContext(ctx, result)
 	return
 }
+
+// renderSolution(id: number, data?: object, format?: 'text' | 'markdown' | 'html', locale?: string): Promise<string>
+func renderSolution(args []js.Value) (res string, err error) {
+	id := args[0].Int()
+	data := make(Map)
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		keys := Object.Call("keys", args[1])
+		for i := 0; i < keys.Length(); i++ {
+			key := keys.Index(i).String()
+			data[key] = args[1].Get(key).String()
+		}
+	}
+	format := RenderText
+	if len(args) > 2 && args[2].Type() == js.TypeString {
+		if format, err = ParseRenderFormat(args[2].String()); err != nil {
+			return
+		}
+	}
+	var locale string
+	if navigator.Truthy() {
+		locale = navigator.Get("language").String()
+	}
+	if len(args) > 3 && args[3].Type() == js.TypeString {
+		locale = args[3].String()
+	}
+	sol, err := defaultAnalyzer.GetLocalizedSolution(id, locale)
+	if err != nil {
+		return
+	}
+	return RenderSolution(sol, data, format), nil
+}
+
+// setAnalyzerOptions(options: { resolveSolutions?: boolean, locale?: string, tags?: string[], minMatch?: number })
+func setAnalyzerOptions(options js.Value) {
+	if v := options.Get("resolveSolutions"); v.Type() == js.TypeBoolean {
+		defaultAnalyzer.ResolveSolutions = v.Bool()
+	}
+	if v := options.Get("locale"); v.Type() == js.TypeString {
+		defaultAnalyzer.Locale = v.String()
+	}
+	if v := options.Get("tags"); v.InstanceOf(Array) {
+		tags := make([]string, v.Length())
+		for i := range tags {
+			tags[i] = v.Index(i).String()
+		}
+		defaultAnalyzer.SolutionTags = tags
+	}
+	if v := options.Get("minMatch"); v.Type() == js.TypeNumber {
+		defaultAnalyzer.MinSolutionMatch = (float32)(v.Float())
+	}
+}
+
+var (
+	searchIndexMux  sync.Mutex
+	searchIndex     *SearchIndex
+	searchIndexTime time.Time
+)
+
+func getSearchIndex() (idx *SearchIndex, err error) {
+	searchIndexMux.Lock()
+	defer searchIndexMux.Unlock()
+	if searchIndex == nil || time.Since(searchIndexTime) > time.Hour {
+		if idx, err = BuildSearchIndex(defaultAnalyzer.DB); err != nil {
+			return
+		}
+		searchIndex, searchIndexTime = idx, time.Now()
+	}
+	return searchIndex, nil
+}
+
+// searchSolutions(query: string | { text?: string, tags?: string[], class?: string, limit?: number }): Promise<SolutionHit[]>
+func searchSolutions(args []js.Value) (hits []*SolutionHit, err error) {
+	var query SearchQuery
+	if q := args[0]; q.Type() == js.TypeString {
+		query.Text = q.String()
+	} else if q.Type() == js.TypeObject {
+		if err = json.Unmarshal(([]byte)(JSON.Call("stringify", q).String()), &query); err != nil {
+			return
+		}
+	}
+	idx, err := getSearchIndex()
+	if err != nil {
+		return
+	}
+	hits = idx.Search(query)
+	for _, hit := range hits {
+		hit.Solution = hit.Solution.Localize(defaultAnalyzer.Locale)
+	}
+	return
+}
@@ -269,7 +269,7 @@ func asyncFuncOf(fn asyncFuncSignature) js.Func {
 func foreachJsIterator(iterator js.Value, callback func(js.Value) error) (err error) {
 	for {
 		res := iterator.Call("next")
-		if !res.Get("done").Bool() {
+		if res.Get("done").Bool() {
 			break
 		}
 		if err = callback(res.Get("value")); err != nil {
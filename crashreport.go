@@ -206,8 +206,9 @@ type CrashReport struct { // ---- Minecraft Crash Report ----
 	OtherDetails  map[string]DetailsItem `json:"others"`        // -- <KEY> --
 }
 
+// ParseCrashReport parses the first crash report in the text, the encoding of the text is detected
 func ParseCrashReport(r io.Reader) (report *CrashReport, err error) {
-	sc := newLineScanner(r)
+	sc := newLineScanner(decodeReader(r))
 	for {
 		if !sc.Scan() {
 			if err = sc.Err(); err == nil {
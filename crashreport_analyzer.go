@@ -0,0 +1,109 @@
+package mcla
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// CrashReportResult is the analysis of a crash report
+type CrashReportResult struct {
+	Report *CrashReport `json:"report"`
+	// Errors are the results of the report's error and its causes, the root error comes first
+	Errors []*ErrorResult `json:"errors"`
+	// Context is the values read from the report, they can be used by the solution placeholders
+	Context map[string]any `json:"context"`
+	// SuspectedMods are the mods in the suspect frames of the error, the head thread and the affected level
+	SuspectedMods []string `json:"suspectedMods"`
+	// Solutions are merged from the errors, filled only when Analyzer.ResolveSolutions is true
+	Solutions []*ResolvedSolution `json:"solutions,omitempty"`
+	// UnresolvedSolutions are merged from the errors, the solutions failed to fetch are still listed once
+	UnresolvedSolutions []UnresolvedSolution `json:"unresolvedSolutions,omitempty"`
+}
+
+// CrashReportContext returns the values of the report which are useful for the solutions, they are
+//
+//	description, thread, minecraftVersion, javaVersion, loader, level, dimension
+//
+// the values not in the report are omitted
+func CrashReportContext(report *CrashReport) map[string]any {
+	ctx := make(map[string]any)
+	set := func(key, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			ctx[key] = value
+		}
+	}
+	set("description", report.Description)
+	set("thread", report.HeadThread.Thread)
+	if details := report.GetDetails("System Details").Details; len(details) > 0 {
+		set("minecraftVersion", details.Get("Minecraft Version"))
+		javaVersion, _ := split(details.Get("Java Version"), ',')
+		set("javaVersion", javaVersion)
+		set("loader", CrashReportLoader(details))
+	}
+	level := report.AffectedLevel.Details
+	set("level", level.Get("Level name"))
+	set("dimension", level.Get("Level dimension"))
+	return ctx
+}
+
+func stacktraceMods(mods []string, st Stacktrace) []string {
+	for _, i := range st.SuspectFrames() {
+		mods = addUnique(mods, st[i].ModName())
+	}
+	return mods
+}
+
+// AnalyzeCrashReport matches the report's error and its causes like DoError,
+// the context of the report is added to the data of the matched solutions,
+// and the stacktraces of the head thread and the affected level are used to find the suspected mods
+func (a *Analyzer) AnalyzeCrashReport(report *CrashReport) (res *CrashReportResult, err error) {
+	return a.analyzeCrashReport(report, a.latestRecorder.Load())
+}
+
+// analyzeCrashReport is AnalyzeCrashReport with the log context of the stream which printed the report
+func (a *Analyzer) analyzeCrashReport(report *CrashReport, recorder *logRecorder) (res *CrashReportResult, err error) {
+	res = &CrashReportResult{
+		Report:  report,
+		Context: CrashReportContext(report),
+		Errors:  make([]*ErrorResult, 0, chainLength(report.Error)),
+	}
+	for jerr := report.Error; jerr != nil; jerr = jerr.CausedBy {
+		er := &ErrorResult{
+			Error: jerr,
+		}
+		if er.Matched, err = a.doError(jerr, recorder); err != nil {
+			return
+		}
+		for i := range er.Matched {
+			m := &er.Matched[i]
+			data := make(map[string]any, len(res.Context)+len(m.Data))
+			for k, v := range res.Context {
+				// the values defined by the entry take precedence
+				if _, ok := m.ErrorDesc.Data[k]; !ok {
+					data[k] = v
+				}
+			}
+			maps.Copy(data, m.Data)
+			m.Data = data
+		}
+		if a.ResolveSolutions {
+			a.ResolveResult(er)
+		}
+		res.Errors = append(res.Errors, er)
+		res.SuspectedMods = stacktraceMods(res.SuspectedMods, jerr.Stacktrace)
+	}
+	res.SuspectedMods = stacktraceMods(res.SuspectedMods, report.HeadThread.Stacktrace)
+	res.SuspectedMods = stacktraceMods(res.SuspectedMods, report.AffectedLevel.Stacktrace)
+	if a.ResolveSolutions && len(res.Errors) > 0 {
+		res.Solutions = ChainResult(res.Errors).Solutions()
+		for _, e := range res.Errors {
+			for _, u := range e.UnresolvedSolutions {
+				if !slices.ContainsFunc(res.UnresolvedSolutions, func(v UnresolvedSolution) bool { return v.ID == u.ID }) {
+					res.UnresolvedSolutions = append(res.UnresolvedSolutions, u)
+				}
+			}
+		}
+	}
+	return
+}
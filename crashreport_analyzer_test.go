@@ -0,0 +1,108 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"strings"
+)
+
+const analyzedCrashReport = `---- Minecraft Crash Report ----
+Description: Ticking entity
+
+net.minecraft.ReportedException: Ticking entity
+	at net.minecraft.server.MinecraftServer.tick(MinecraftServer.java:1)
+Caused by: java.lang.RuntimeException: Mod create requires flywheel 0.6.10 or above
+	at com.simibubi.create.Create.init(Create.java:1) ~[create-1.20.1-0.5.1.jar:?]
+
+
+A detailed walkthrough of the error, its code path and all known details is as follows:
+---------------------------------------------------------------------------------------
+
+-- Head --
+Thread: Server thread
+Stacktrace:
+	at com.jozufozu.flywheel.Flywheel.tick(Flywheel.java:1) ~[flywheel-forge-1.20.1-0.6.10.jar:?]
+
+-- Affected level --
+Details:
+	Level name: ServerLevel[New World]
+	Level dimension: minecraft:overworld
+
+-- System Details --
+Details:
+	Minecraft Version: 1.20.1
+	Java Version: 17.0.8, Microsoft
+	Forge: net.minecraftforge:47.1.0
+`
+
+func TestAnalyzeCrashReport(t *testing.T) {
+	report, err := ParseCrashReport(strings.NewReader(analyzedCrashReport))
+	if err != nil {
+		t.Fatalf("ParseCrashReport: %v", err)
+	}
+	db := &mapErrorDB{
+		errors: []*ErrorDesc{
+			{ID: 1, Error: "java.lang.RuntimeException", Pattern: `^Mod (?P<mod>\w+) requires`, Solutions: []int{1}},
+		},
+		solutions: map[int]*SolutionDesc{1: {Description: "Update ${mod} for Minecraft ${minecraftVersion}"}},
+	}
+	a := NewAnalyzer(db)
+	a.ResolveSolutions = true
+	res, err := a.AnalyzeCrashReport(report)
+	if err != nil {
+		t.Fatalf("AnalyzeCrashReport: %v", err)
+	}
+	if len(res.Errors) != 2 || len(res.Errors[0].Matched) != 0 || len(res.Errors[1].Matched) != 1 {
+		t.Fatalf("Expect only the cause is matched, got %d results", len(res.Errors))
+	}
+	expectContext := map[string]any{
+		"description":      "Ticking entity",
+		"thread":           "Server thread",
+		"minecraftVersion": "1.20.1",
+		"javaVersion":      "17.0.8",
+		"loader":           "Forge 47.1.0",
+		"level":            "ServerLevel[New World]",
+		"dimension":        "minecraft:overworld",
+	}
+	for k, v := range expectContext {
+		if res.Context[k] != v {
+			t.Errorf("Expect context %s = %q, got %q", k, v, res.Context[k])
+		}
+	}
+	data := res.Errors[1].Matched[0].TemplateData()
+	if data["mod"] != "create" || data["minecraftVersion"] != "1.20.1" {
+		t.Errorf("Expect the captured groups and the context in the data, got %v", data)
+	}
+	if len(res.Solutions) != 1 || res.Solutions[0].Solution.Description != "Update create for Minecraft 1.20.1" {
+		t.Errorf("Unexpected solutions %v", res.Solutions)
+	}
+	if strings.Join(res.SuspectedMods, ",") != "create,flywheel" {
+		t.Errorf("Expect the suspected mods create and flywheel, got %v", res.SuspectedMods)
+	}
+}
+
+func TestAnalyzeCrashReportUnresolved(t *testing.T) {
+	report, err := ParseCrashReport(strings.NewReader(analyzedCrashReport))
+	if err != nil {
+		t.Fatalf("ParseCrashReport: %v", err)
+	}
+	db := &flakyErrorDB{mapErrorDB{
+		errors: []*ErrorDesc{
+			{ID: 1, Error: "java.lang.RuntimeException", Pattern: `^Mod (?P<mod>\w+) requires`, Solutions: []int{1, 2}},
+		},
+		solutions: map[int]*SolutionDesc{1: {Description: "Update ${mod}"}},
+	}}
+	a := NewAnalyzer(db)
+	a.ResolveSolutions = true
+	res, err := a.AnalyzeCrashReport(report)
+	if err != nil {
+		t.Fatalf("Expect the analysis is not aborted, got %v", err)
+	}
+	if len(res.Solutions) != 1 || res.Solutions[0].ID != 1 {
+		t.Errorf("Expect the solution 1 is resolved, got %v", res.Solutions)
+	}
+	if len(res.UnresolvedSolutions) != 1 || res.UnresolvedSolutions[0].ID != 2 {
+		t.Errorf("Expect the solution 2 is unresolved, got %v", res.UnresolvedSolutions)
+	}
+}
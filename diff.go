@@ -0,0 +1,246 @@
+package mcla
+
+import (
+	"slices"
+	"strings"
+)
+
+type DiffStatus string
+
+const (
+	DiffUnchanged DiffStatus = "unchanged"
+	DiffAdded     DiffStatus = "added"
+	DiffRemoved   DiffStatus = "removed"
+	DiffChanged   DiffStatus = "changed"
+)
+
+// DiffEntry is a difference of a value between the old and the new report
+type DiffEntry struct {
+	Status DiffStatus `json:"status"`
+	Key    string     `json:"key"`
+	Old    string     `json:"old,omitempty"`
+	New    string     `json:"new,omitempty"`
+}
+
+type CrashReportDiff struct {
+	Description DiffEntry `json:"description"`
+	// SameError reports whether the errors have the same fingerprint
+	SameError bool `json:"sameError"`
+	// ErrorChain is the aligned classes and normalized messages of the cause chains
+	ErrorChain []DiffEntry `json:"errorChain"`
+	// Mods are the added, removed and updated mods
+	Mods []DiffEntry `json:"mods"`
+	// Details are the changed system details, except the mod lists
+	Details []DiffEntry `json:"details"`
+}
+
+// modListKeys are the system details which list the mods, they are compared as mods
+var modListKeys = []string{"MOD LIST", "FABRIC MODS", "QUILT MODS"}
+
+// volatileDetails are different in every crash, so they are not compared
+var volatileDetails = []string{"MEMORY", "CRASH REPORT UUID"}
+
+// CrashReportMods returns the mod IDs and their versions in the system details of the report.
+// The mod list of Forge and NeoForge, and the mod lists of Fabric and Quilt are supported
+func CrashReportMods(report *CrashReport) map[string]string {
+	details := report.GetDetails("System Details").Details
+	mods := make(map[string]string)
+	// e.g. `examplemod-1.0.jar |Example Mod |examplemod |1.0 |DONE |Manifest: NOSIGNATURE`
+	// or `| LCHIJA | examplemod | 1.0 | examplemod-1.0.jar | None |` before Minecraft 1.13
+	for _, line := range details.GetValues("Mod List") {
+		// the ID and the version are the third and the fourth columns in both formats
+		fields := strings.Split(line, "|")
+		if len(fields) < 4 {
+			continue
+		}
+		id, version := strings.TrimSpace(fields[2]), strings.TrimSpace(fields[3])
+		if id != "" && id != "ID" && !strings.HasPrefix(id, ":-") {
+			mods[id] = version
+		}
+	}
+	addFabricMods(mods, details.GetValues("Fabric Mods"))
+	quilt := details.GetValues("Quilt Mods")
+	if len(quilt) > 0 && strings.HasPrefix(quilt[0], "|") {
+		addQuiltMods(mods, quilt)
+	} else {
+		// the versions of Quilt Loader before the table use the format of Fabric
+		addFabricMods(mods, quilt)
+	}
+	return mods
+}
+
+// addFabricMods parses the lines like `fabric-api: Fabric API 0.92.0+1.20.1`
+func addFabricMods(mods map[string]string, lines []string) {
+	for _, line := range lines {
+		id, rest, ok := strings.Cut(line, ": ")
+		if !ok || strings.ContainsAny(id, " \t") {
+			continue
+		}
+		_, version := rsplit(rest, ' ')
+		mods[id] = version
+	}
+}
+
+// addQuiltMods parses the table of Quilt Loader, the columns are found by the header
+// e.g. `| Index | Name | ID | Version | Flags | File(s) |`
+// and `|     1 | Quilt Loader | quilt_loader | 0.21.0 | ----- | <classpath> |`
+func addQuiltMods(mods map[string]string, lines []string) {
+	idCol, versionCol := -1, -1
+	for _, line := range lines {
+		fields := strings.Split(line, "|")
+		for i, f := range fields {
+			fields[i] = strings.TrimSpace(f)
+		}
+		if idCol < 0 {
+			idCol, versionCol = slices.Index(fields, "ID"), slices.Index(fields, "Version")
+			if versionCol < 0 {
+				idCol = -1
+			}
+			continue
+		}
+		if idCol >= len(fields) || versionCol >= len(fields) {
+			continue
+		}
+		id := fields[idCol]
+		if id != "" && !strings.HasPrefix(id, "-") && !strings.HasPrefix(id, ":-") {
+			mods[id] = fields[versionCol]
+		}
+	}
+}
+
+// diffLines aligns two lists by their longest common parts
+func diffLines(a, b []string) (entries []DiffEntry) {
+	n, a1, a2, b1, b2 := lcsSplit(a, b)
+	if n == 0 {
+		for _, v := range a {
+			entries = append(entries, DiffEntry{Status: DiffRemoved, Key: v})
+		}
+		for _, v := range b {
+			entries = append(entries, DiffEntry{Status: DiffAdded, Key: v})
+		}
+		return
+	}
+	entries = diffLines(a1, b1)
+	for _, v := range a[len(a1) : len(a1)+n] {
+		entries = append(entries, DiffEntry{Status: DiffUnchanged, Key: v})
+	}
+	return append(entries, diffLines(a2, b2)...)
+}
+
+func chainLines(jerr *JavaError) (lines []string) {
+	for ; jerr != nil; jerr = jerr.CausedBy {
+		line := jerr.Class
+		if msg := NormalizeMessage(jerr.Message); msg != "" {
+			line += ": " + msg
+		}
+		lines = append(lines, line)
+	}
+	return
+}
+
+// diffMaps compares the values of the keys, the unchanged keys are omitted
+func diffMaps(a, b map[string]string) (entries []DiffEntry) {
+	for key, old := range a {
+		if v, ok := b[key]; !ok {
+			entries = append(entries, DiffEntry{Status: DiffRemoved, Key: key, Old: old})
+		} else if v != old {
+			entries = append(entries, DiffEntry{Status: DiffChanged, Key: key, Old: old, New: v})
+		}
+	}
+	for key, v := range b {
+		if _, ok := a[key]; !ok {
+			entries = append(entries, DiffEntry{Status: DiffAdded, Key: key, New: v})
+		}
+	}
+	slices.SortFunc(entries, func(x, y DiffEntry) int {
+		return strings.Compare(x.Key, y.Key)
+	})
+	return
+}
+
+func comparableDetails(report *CrashReport) map[string]string {
+	details := report.GetDetails("System Details").Details
+	values := make(map[string]string, len(details))
+	for key := range details {
+		if !slices.Contains(modListKeys, key) && !slices.Contains(volatileDetails, key) {
+			values[key] = details.Get(key)
+		}
+	}
+	return values
+}
+
+// DiffCrashReports compares the old report with the new one
+func DiffCrashReports(old, cur *CrashReport) *CrashReportDiff {
+	diff := &CrashReportDiff{
+		Description: DiffEntry{
+			Status: DiffUnchanged,
+			Key:    "Description",
+			Old:    old.Description,
+			New:    cur.Description,
+		},
+	}
+	if old.Description != cur.Description {
+		diff.Description.Status = DiffChanged
+	}
+	if old.Error != nil && cur.Error != nil {
+		diff.SameError = Fingerprint(old.Error) == Fingerprint(cur.Error)
+	}
+	diff.ErrorChain = diffLines(chainLines(old.Error), chainLines(cur.Error))
+	diff.Mods = diffMaps(CrashReportMods(old), CrashReportMods(cur))
+	diff.Details = diffMaps(comparableDetails(old), comparableDetails(cur))
+	return diff
+}
+
+// ErrorDiff is an error fingerprint in the logs, and how many times it occurred in each log
+type ErrorDiff struct {
+	Fingerprint string     `json:"fingerprint"`
+	OldCount    int        `json:"oldCount"`
+	NewCount    int        `json:"newCount"`
+	Example     *JavaError `json:"example"`
+}
+
+type LogDiff struct {
+	// New errors only occurred in the new log
+	New []*ErrorDiff `json:"new"`
+	// Fixed errors only occurred in the old log
+	Fixed []*ErrorDiff `json:"fixed"`
+	// Unchanged errors occurred in both logs
+	Unchanged []*ErrorDiff `json:"unchanged"`
+}
+
+// DiffLogErrors compares the error fingerprints of two logs, the errors are the ones ScanJavaErrors returns.
+// The example of an error is from the new log if it occurred there
+func DiffLogErrors(old, cur []*JavaError) *LogDiff {
+	var (
+		fingerprints = make(map[string]*ErrorDiff)
+		order        []*ErrorDiff
+	)
+	get := func(jerr *JavaError) *ErrorDiff {
+		fp := Fingerprint(jerr)
+		d, ok := fingerprints[fp]
+		if !ok {
+			d = &ErrorDiff{Fingerprint: fp, Example: jerr}
+			fingerprints[fp] = d
+			order = append(order, d)
+		}
+		return d
+	}
+	for _, jerr := range cur {
+		get(jerr).NewCount++
+	}
+	for _, jerr := range old {
+		get(jerr).OldCount++
+	}
+	diff := new(LogDiff)
+	for _, d := range order {
+		switch {
+		case d.OldCount == 0:
+			diff.New = append(diff.New, d)
+		case d.NewCount == 0:
+			diff.Fixed = append(diff.Fixed, d)
+		default:
+			diff.Unchanged = append(diff.Unchanged, d)
+		}
+	}
+	return diff
+}
@@ -0,0 +1,132 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"strings"
+)
+
+const diffCrashReport = `---- Minecraft Crash Report ----
+Description: Ticking entity
+
+java.lang.RuntimeException: Ticking entity
+	at net.minecraft.server.MinecraftServer.tick(MinecraftServer.java:1)
+Caused by: java.lang.NullPointerException: Entity 1234 is null
+	at com.example.mod.Mob.tick(Mob.java:1)
+
+
+A detailed walkthrough of the error, its code path and all known details is as follows:
+---------------------------------------------------------------------------------------
+
+-- System Details --
+Details:
+	Minecraft Version: 1.20.1
+	Memory: 1024 bytes
+	Mod List:
+		forge-47.1.0.jar |Forge       |forge      |47.1.0 |DONE |Manifest: NOSIGNATURE
+		example-1.0.jar  |Example Mod |examplemod |1.0    |DONE |Manifest: NOSIGNATURE
+		jei-15.2.0.jar   |JEI         |jei        |15.2.0 |DONE |Manifest: NOSIGNATURE
+`
+
+func TestCrashReportMods(t *testing.T) {
+	report, err := ParseCrashReport(strings.NewReader(diffCrashReport))
+	if err != nil {
+		t.Fatalf("ParseCrashReport: %v", err)
+	}
+	mods := CrashReportMods(report)
+	if len(mods) != 3 || mods["forge"] != "47.1.0" || mods["examplemod"] != "1.0" || mods["jei"] != "15.2.0" {
+		t.Errorf("Unexpected mods %v", mods)
+	}
+}
+
+func TestCrashReportModsQuilt(t *testing.T) {
+	text := strings.Replace(diffCrashReport, "\tMod List:\n", "\tQuilt Mods: \n"+
+		"\t\t| Index | Name         | ID           | Version | Flags | File(s)     |\n"+
+		"\t\t| ----: | ------------ | ------------ | ------- | ----- | ----------- |\n"+
+		"\t\t|     0 | Minecraft    | minecraft    | 1.20.1  | ----- | <game jar>  |\n"+
+		"\t\t|     1 | Quilt Loader | quilt_loader | 0.21.0  | ----- | <classpath> |\n"+
+		"\tOther List:\n", 1)
+	report, err := ParseCrashReport(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("ParseCrashReport: %v", err)
+	}
+	mods := CrashReportMods(report)
+	if len(mods) != 2 || mods["minecraft"] != "1.20.1" || mods["quilt_loader"] != "0.21.0" {
+		t.Errorf("Unexpected mods %v", mods)
+	}
+
+	cur, err := ParseCrashReport(strings.NewReader(strings.Replace(text, "| 0.21.0 ", "| 0.22.0 ", 1)))
+	if err != nil {
+		t.Fatalf("ParseCrashReport: %v", err)
+	}
+	diff := DiffCrashReports(report, cur)
+	if len(diff.Mods) != 1 || diff.Mods[0] != (DiffEntry{Status: DiffChanged, Key: "quilt_loader", Old: "0.21.0", New: "0.22.0"}) {
+		t.Errorf("Expect the Quilt Loader is updated, got %v", diff.Mods)
+	}
+}
+
+func TestDiffCrashReports(t *testing.T) {
+	old, err := ParseCrashReport(strings.NewReader(diffCrashReport))
+	if err != nil {
+		t.Fatalf("ParseCrashReport: %v", err)
+	}
+	text := diffCrashReport
+	text = strings.Replace(text, "Entity 1234", "Entity 5678", 1)
+	text = strings.Replace(text, "1024 bytes", "2048 bytes", 1)
+	text = strings.Replace(text, "1.20.1", "1.20.2", 1)
+	text = strings.Replace(text, "|jei        |15.2.0", "|jei        |15.3.0", 1)
+	text = strings.Replace(text, "\t\texample-1.0.jar  |Example Mod |examplemod |1.0    |DONE |Manifest: NOSIGNATURE\n", "", 1)
+	text += "\t\tnew-1.0.jar  |New Mod |newmod |1.0    |DONE |Manifest: NOSIGNATURE\n"
+	cur, err := ParseCrashReport(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("ParseCrashReport: %v", err)
+	}
+
+	diff := DiffCrashReports(old, cur)
+	if diff.Description.Status != DiffUnchanged {
+		t.Errorf("Expect the description is unchanged, got %s", diff.Description.Status)
+	}
+	if !diff.SameError {
+		t.Errorf("Expect the errors only differ in numbers are the same")
+	}
+	for _, e := range diff.ErrorChain {
+		if e.Status != DiffUnchanged {
+			t.Errorf("Unexpected error chain entry %#v", e)
+		}
+	}
+	expectMods := []DiffEntry{
+		{Status: DiffRemoved, Key: "examplemod", Old: "1.0"},
+		{Status: DiffChanged, Key: "jei", Old: "15.2.0", New: "15.3.0"},
+		{Status: DiffAdded, Key: "newmod", New: "1.0"},
+	}
+	if len(diff.Mods) != len(expectMods) {
+		t.Fatalf("Expect mods diff %v, got %v", expectMods, diff.Mods)
+	}
+	for i, e := range expectMods {
+		if diff.Mods[i] != e {
+			t.Errorf("Expect mods diff %v, got %v", e, diff.Mods[i])
+		}
+	}
+	if len(diff.Details) != 1 || diff.Details[0].Key != "MINECRAFT VERSION" || diff.Details[0].New != "1.20.2" {
+		t.Errorf("Expect only the Minecraft version is changed, got %v", diff.Details)
+	}
+}
+
+func TestDiffLogErrors(t *testing.T) {
+	oom := &JavaError{Class: "java.lang.OutOfMemoryError", Message: "Java heap space"}
+	npe := &JavaError{Class: "java.lang.NullPointerException", Message: "Entity 1 is null"}
+	npe2 := &JavaError{Class: "java.lang.NullPointerException", Message: "Entity 2 is null"}
+	ise := &JavaError{Class: "java.lang.IllegalStateException", Message: "Bad state"}
+
+	diff := DiffLogErrors([]*JavaError{oom, npe}, []*JavaError{npe2, npe2, ise})
+	if len(diff.New) != 1 || diff.New[0].Example != ise {
+		t.Errorf("Expect the new error is %v, got %v", ise, diff.New)
+	}
+	if len(diff.Fixed) != 1 || diff.Fixed[0].Example != oom {
+		t.Errorf("Expect the fixed error is %v, got %v", oom, diff.Fixed)
+	}
+	if len(diff.Unchanged) != 1 || diff.Unchanged[0].OldCount != 1 || diff.Unchanged[0].NewCount != 2 {
+		t.Errorf("Unexpected unchanged errors %v", diff.Unchanged)
+	}
+}
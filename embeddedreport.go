@@ -0,0 +1,180 @@
+package mcla
+
+import (
+	"context"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// logLineRe matches the lines which start a new log entry, they are the end of a printed crash report.
+// e.g. `[12:00:00] [main/INFO]:`, `[27Jan2024 12:00:00.000] [main/INFO]`, `2024-01-01 12:00:00`,
+// and `#@!@# Game crashed! Crash report saved to: #@!@# <path>`
+var logLineRe = regexp.MustCompile(`^(?:\[\d{1,2}:\d{2}:\d{2}|\[\d{1,2}[A-Za-z]{3}\d{4} |\d{4}-\d{2}-\d{2}[ T]\d{2}:\d{2}|#@!@#)`)
+
+// crashReportSavedRe matches the line which tells where the crash report is saved
+var crashReportSavedRe = regexp.MustCompile(`(?:Crash report saved to:(?:\s*#@!@#)?|This crash report has been saved to:)\s*(.+?)\s*$`)
+
+// EmbeddedCrashReport is a crash report printed in a log
+type EmbeddedCrashReport struct {
+	*CrashReport
+	// LineNo is the line of the report header
+	LineNo int `json:"lineNo"`
+	// SavedTo is the path where the game saved the report, if it's logged right after the report
+	SavedTo string `json:"savedTo,omitempty"`
+}
+
+// EmbeddedReportResult is the result of a crash report printed in a log stream,
+// the line numbers of the report's errors are converted to the lines of the log
+type EmbeddedReportResult struct {
+	*CrashReportResult
+	// LineNo is the line of the report header
+	LineNo  int    `json:"lineNo"`
+	SavedTo string `json:"savedTo,omitempty"`
+}
+
+type logReportsKey struct{}
+
+type logReports struct {
+	mux  sync.Mutex
+	list []*EmbeddedReportResult
+}
+
+func (r *logReports) add(res *EmbeddedReportResult) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.list = append(r.list, res)
+}
+
+// LogCrashReports returns the results of the crash reports printed in the log stream in the order of the lines.
+// The context must be the one returned by DoLogStream, and the reports are complete once the stream is done
+func LogCrashReports(ctx context.Context) []*EmbeddedReportResult {
+	r, ok := ctx.Value(logReportsKey{}).(*logReports)
+	if !ok {
+		return nil
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	list := slices.Clone(r.list)
+	slices.SortFunc(list, func(a, b *EmbeddedReportResult) int {
+		return a.LineNo - b.LineNo
+	})
+	return list
+}
+
+// toLogLineNo converts the line numbers of the report's error and its causes to the lines of the log
+func (r *EmbeddedCrashReport) toLogLineNo() {
+	for jerr := r.Error; jerr != nil; jerr = jerr.CausedBy {
+		jerr.LineNo += r.LineNo - 1
+	}
+}
+
+func isCrashReportHeader(line string) bool {
+	return strings.HasSuffix(strings.ToUpper(strings.TrimSpace(line)), crashReportHeader)
+}
+
+// crashReportFilter passes the log through line by line, except the crash reports in it are replaced by empty lines,
+// so the errors in the reports are not scanned again, and the line numbers are kept
+type crashReportFilter struct {
+	sc       *lineScanner
+	onReport func(*EmbeddedCrashReport)
+
+	buf     []byte
+	pending []string // the lines to pass through
+	report  []string // the lines of the crash report being read
+	lineNo  int
+	err     error
+}
+
+func (f *crashReportFilter) Read(buf []byte) (n int, err error) {
+	for len(f.buf) == 0 {
+		if len(f.pending) > 0 {
+			f.buf = append(f.buf[:0], f.pending[0]...)
+			f.buf = append(f.buf, '\n')
+			f.pending = f.pending[1:]
+			break
+		}
+		if f.err != nil {
+			return 0, f.err
+		}
+		f.next()
+	}
+	n = copy(buf, f.buf)
+	f.buf = f.buf[n:]
+	return
+}
+
+// next reads the next line into pending
+func (f *crashReportFilter) next() {
+	if !f.sc.Scan() {
+		if f.err = f.sc.Err(); f.err == nil {
+			f.err = io.EOF
+		}
+		f.endReport("")
+		return
+	}
+	line := f.sc.Text()
+	if isCrashReportHeader(line) {
+		f.endReport("")
+		f.report = []string{line}
+		f.lineNo = f.sc.Count()
+		return
+	}
+	if f.report == nil {
+		f.pending = append(f.pending, line)
+		return
+	}
+	if logLineRe.MatchString(line) {
+		f.endReport(line)
+		f.pending = append(f.pending, line)
+		return
+	}
+	f.report = append(f.report, line)
+}
+
+// endReport parses the collected report, if it cannot be parsed, its lines are passed through as log lines
+func (f *crashReportFilter) endReport(next string) {
+	if f.report == nil {
+		return
+	}
+	lines := f.report
+	f.report = nil
+	report, err := ParseCrashReport(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil || report.Error == nil {
+		f.pending = append(f.pending, lines...)
+		return
+	}
+	res := &EmbeddedCrashReport{
+		CrashReport: report,
+		LineNo:      f.lineNo,
+	}
+	if m := crashReportSavedRe.FindStringSubmatch(next); m != nil {
+		res.SavedTo = m[1]
+	}
+	f.onReport(res)
+	for range lines {
+		f.pending = append(f.pending, "")
+	}
+}
+
+// ScanLog scans the java errors and the crash reports printed in a log, e.g. latest.log or a server log.
+// The errors in the crash reports are not reported again by onError.
+// A crash report is reported once its last line is read, so the callbacks are not strictly in the order of the lines,
+// use the line numbers to sort them if needed
+func ScanLog(r io.Reader, onError func(*JavaError), onReport func(*EmbeddedCrashReport)) error {
+	f := &crashReportFilter{
+		sc:       newLineScanner(decodeReader(r)),
+		onReport: onReport,
+	}
+	return scanJavaErrors(f, onError, nil)
+}
+
+// ParseCrashReports returns all the crash reports printed in the log
+func ParseCrashReports(r io.Reader) (reports []*EmbeddedCrashReport, err error) {
+	err = ScanLog(r, func(*JavaError) {}, func(report *EmbeddedCrashReport) {
+		reports = append(reports, report)
+	})
+	return
+}
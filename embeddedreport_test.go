@@ -0,0 +1,155 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"context"
+	"strings"
+	"testing/fstest"
+)
+
+const embeddedReportLog = `[00:00:00] [main/INFO]: Starting
+[00:00:01] [main/ERROR]: Failed
+java.lang.IllegalStateException: Bad state
+	at com.example.mod.Mob.tick(Mob.java:1)
+[00:00:02] [Render thread/INFO] [STDOUT/]: [net.minecraft.server.Bootstrap:realStdoutPrintln:1]: ---- Minecraft Crash Report ----
+// Who set us up the TNT?
+
+Time: 2024-01-01 00:00:00
+Description: Ticking entity
+
+java.lang.NullPointerException: Entity 1234 is null
+	at com.example.mod.Mob.tick(Mob.java:1)
+
+
+A detailed walkthrough of the error, its code path and all known details is as follows:
+---------------------------------------------------------------------------------------
+
+-- System Details --
+Details:
+	Minecraft Version: 1.20.1
+
+#@!@# Game crashed! Crash report saved to: #@!@# /mc/crash-reports/crash-1.txt
+[00:00:03] [main/INFO]: Restarting
+---- Minecraft Crash Report ----
+Description: Exception in server tick loop
+
+java.lang.OutOfMemoryError: Java heap space
+	at net.minecraft.server.MinecraftServer.tick(MinecraftServer.java:1)
+
+[00:00:04] [main/ERROR]: Failed again
+java.lang.IllegalStateException: Bad state
+	at com.example.mod.Mob.tick(Mob.java:1)
+[00:00:05] [main/INFO]: Stopping
+`
+
+func TestScanLog(t *testing.T) {
+	var (
+		errs    []*JavaError
+		reports []*EmbeddedCrashReport
+	)
+	err := ScanLog(strings.NewReader(embeddedReportLog), func(jerr *JavaError) {
+		errs = append(errs, jerr)
+	}, func(report *EmbeddedCrashReport) {
+		reports = append(reports, report)
+	})
+	if err != nil {
+		t.Fatalf("ScanLog: %v", err)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("Expect 2 errors outside the crash reports, got %d", len(errs))
+	}
+	if errs[0].LineNo != 3 || errs[1].LineNo != 31 {
+		t.Errorf("Expect the errors at line 3 and 31, got %d and %d", errs[0].LineNo, errs[1].LineNo)
+	}
+	if len(reports) != 2 {
+		t.Fatalf("Expect 2 crash reports, got %d", len(reports))
+	}
+	first, second := reports[0], reports[1]
+	if first.LineNo != 5 || first.Description != "Ticking entity" || first.Error.Class != "java.lang.NullPointerException" {
+		t.Errorf("Unexpected first report at line %d: %q %v", first.LineNo, first.Description, first.Error)
+	}
+	if first.SavedTo != "/mc/crash-reports/crash-1.txt" {
+		t.Errorf("Unexpected saved path %q", first.SavedTo)
+	}
+	if first.GetDetails("System Details").Details.Get("Minecraft Version") != "1.20.1" {
+		t.Errorf("Expect the system details are parsed")
+	}
+	if second.LineNo != 24 || second.Error.Class != "java.lang.OutOfMemoryError" || second.SavedTo != "" {
+		t.Errorf("Unexpected second report at line %d: %v, saved to %q", second.LineNo, second.Error, second.SavedTo)
+	}
+}
+
+func TestParseCrashReports(t *testing.T) {
+	reports, err := ParseCrashReports(strings.NewReader(diffCrashReport))
+	if err != nil {
+		t.Fatalf("ParseCrashReports: %v", err)
+	}
+	if len(reports) != 1 || reports[0].LineNo != 1 || reports[0].Description != "Ticking entity" {
+		t.Errorf("Expect the crash report file is parsed as one report, got %d", len(reports))
+	}
+}
+
+func TestDoLogStreamEmbeddedReports(t *testing.T) {
+	db := &mapErrorDB{
+		errors: []*ErrorDesc{
+			{ID: 1, Error: "java.lang.NullPointerException", Pattern: `^Entity \d+ is null`, Solutions: []int{1}},
+		},
+	}
+	a := NewAnalyzer(db)
+	result, ctx := a.DoLogStream(context.Background(), strings.NewReader(embeddedReportLog))
+	lines := make(map[int]*ErrorResult)
+	for res := range result {
+		if _, ok := lines[res.Error.LineNo]; ok {
+			t.Errorf("The error at line %d is reported twice", res.Error.LineNo)
+		}
+		lines[res.Error.LineNo] = res
+	}
+	if err := context.Cause(ctx); err != nil {
+		t.Fatalf("DoLogStream: %v", err)
+	}
+	// the errors of the reports have the line numbers in the log
+	for _, lineNo := range []int{3, 11, 27, 31} {
+		if lines[lineNo] == nil {
+			t.Errorf("Expect an error at line %d", lineNo)
+		}
+	}
+	if len(lines) != 4 {
+		t.Errorf("Expect 4 errors, got %d", len(lines))
+	}
+	if npe := lines[11]; npe != nil && (len(npe.Matched) != 1 || npe.Matched[0].Data["minecraftVersion"] != "1.20.1") {
+		t.Errorf("Expect the error in the report is matched with the report context, got %v", npe.Matched)
+	}
+
+	reports := LogCrashReports(ctx)
+	if len(reports) != 2 {
+		t.Fatalf("Expect 2 crash reports, got %d", len(reports))
+	}
+	if reports[0].LineNo != 5 || reports[0].SavedTo != "/mc/crash-reports/crash-1.txt" || reports[0].Report.Description != "Ticking entity" {
+		t.Errorf("Unexpected first report at line %d: %q, saved to %q", reports[0].LineNo, reports[0].Report.Description, reports[0].SavedTo)
+	}
+	if len(reports[0].Errors) != 1 || reports[0].Errors[0] != lines[11] {
+		t.Errorf("Expect the report result has the sent error")
+	}
+	if reports[1].LineNo != 24 || reports[1].Report.Error.Class != "java.lang.OutOfMemoryError" {
+		t.Errorf("Unexpected second report at line %d: %v", reports[1].LineNo, reports[1].Report.Error)
+	}
+}
+
+func TestAnalyzeFileEmbeddedReports(t *testing.T) {
+	files, err := FindInstanceFiles(fstest.MapFS{"logs/latest.log": {Data: ([]byte)(embeddedReportLog)}})
+	if err != nil || len(files) != 1 {
+		t.Fatalf("FindInstanceFiles: %d files, %v", len(files), err)
+	}
+	res := NewAnalyzer(&mapErrorDB{}).AnalyzeFile(context.Background(), files[0])
+	if res.Failure != "" {
+		t.Fatalf("AnalyzeFile: %s", res.Failure)
+	}
+	if len(res.EmbeddedReports) != 2 || res.EmbeddedReports[0].LineNo != 5 || res.EmbeddedReports[1].LineNo != 24 {
+		t.Fatalf("Expect the 2 crash reports are in the result, got %d", len(res.EmbeddedReports))
+	}
+	if len(res.Errors) != 4 || res.Errors[1].Error.LineNo != 11 || res.Errors[1].File != "logs/latest.log" {
+		t.Errorf("Expect the errors of the log and the reports sorted by lines, got %d", len(res.Errors))
+	}
+}
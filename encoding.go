@@ -0,0 +1,354 @@
+package mcla
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// The encodings of the logs, players on Windows often send logs in their system encodings,
+// and the logs redirected by PowerShell are in UTF-16
+const (
+	EncodingUTF8     = "utf-8"
+	EncodingUTF16LE  = "utf-16le"
+	EncodingUTF16BE  = "utf-16be"
+	EncodingGBK      = "gbk"
+	EncodingShiftJIS = "shift_jis"
+	EncodingCP1251   = "windows-1251"
+)
+
+var encodingAliases = map[string]string{
+	"utf8":     EncodingUTF8,
+	"utf16le":  EncodingUTF16LE,
+	"utf16be":  EncodingUTF16BE,
+	"gb2312":   EncodingGBK,
+	"gb18030":  EncodingGBK,
+	"cp936":    EncodingGBK,
+	"sjis":     EncodingShiftJIS,
+	"shiftjis": EncodingShiftJIS,
+	"cp932":    EncodingShiftJIS,
+	"cp1251":   EncodingCP1251,
+}
+
+func lookupEncoding(name string) (enc encoding.Encoding, ok bool) {
+	switch name {
+	case EncodingUTF8:
+		return encoding.Nop, true
+	case EncodingUTF16LE:
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), true
+	case EncodingUTF16BE:
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), true
+	case EncodingGBK:
+		// GB18030 is the superset of GBK and GB2312
+		return simplifiedchinese.GB18030, true
+	case EncodingShiftJIS:
+		return japanese.ShiftJIS, true
+	case EncodingCP1251:
+		return charmap.Windows1251, true
+	}
+	return nil, false
+}
+
+// NormalizeEncoding returns the canonical name of the encoding, or an empty string if it's not supported
+func NormalizeEncoding(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := encodingAliases[strings.ReplaceAll(name, "-", "")]; ok {
+		return alias
+	}
+	if _, ok := lookupEncoding(name); ok {
+		return name
+	}
+	return ""
+}
+
+// sniffSize is the max length of the sample to detect the encoding
+const sniffSize = 64 * 1024
+
+// validUTF8 is utf8.Valid, but the sample is allowed to end in the middle of a character
+func validUTF8(sample []byte) bool {
+	for i := 1; i < utf8.UTFMax && i <= len(sample); i++ {
+		if utf8.RuneStart(sample[len(sample)-i]) {
+			if !utf8.FullRune(sample[len(sample)-i:]) {
+				sample = sample[:len(sample)-i]
+			}
+			break
+		}
+	}
+	return utf8.Valid(sample)
+}
+
+// utf16Order guesses the byte order of UTF-16 text without BOM, the logs are mostly ASCII,
+// so half of the bytes are zeros
+func utf16Order(sample []byte) string {
+	var even, odd int
+	n := len(sample) / 2 * 2
+	if n < 4 {
+		return ""
+	}
+	for i := 0; i < n; i += 2 {
+		if sample[i] == 0 {
+			even++
+		}
+		if sample[i+1] == 0 {
+			odd++
+		}
+	}
+	half := n / 2
+	switch {
+	case odd*10 >= half*3 && even*20 < half:
+		return EncodingUTF16LE
+	case even*10 >= half*3 && odd*20 < half:
+		return EncodingUTF16BE
+	}
+	return ""
+}
+
+// The scores are how many bytes look like the common characters of the encoding,
+// and the invalid sequences are penalized
+const invalidPenalty = 4
+
+func gbkScore(sample []byte) (score int) {
+	for i := 0; i < len(sample); i++ {
+		b := sample[i]
+		if b < 0x80 {
+			continue
+		}
+		if b == 0x80 || b == 0xff || i+1 >= len(sample) {
+			score -= invalidPenalty
+			continue
+		}
+		t := sample[i+1]
+		if t < 0x40 || t == 0x7f || t == 0xff {
+			score -= invalidPenalty
+			continue
+		}
+		// the punctuations and the characters of GB2312
+		if 0xa1 <= b && b <= 0xf7 && t >= 0xa1 {
+			score += 2
+		}
+		i++
+	}
+	return
+}
+
+func shiftJISScore(sample []byte) (score int) {
+	for i := 0; i < len(sample); i++ {
+		b := sample[i]
+		switch {
+		case b < 0x80:
+			continue
+		case 0xa1 <= b && b <= 0xdf: // half-width katakana, rarely used
+			continue
+		case (0x81 <= b && b <= 0x9f || 0xe0 <= b && b <= 0xef) && i+1 < len(sample):
+			if t := sample[i+1]; 0x40 <= t && t <= 0xfc && t != 0x7f {
+				score += 2
+				i++
+				continue
+			}
+		}
+		score -= invalidPenalty
+	}
+	return
+}
+
+func cp1251Score(sample []byte) (score int) {
+	for _, b := range sample {
+		switch {
+		case b >= 0xc0 || b == 0xa8 || b == 0xb8: // А-я, Ё and ё
+			score++
+		case b == 0x98: // undefined
+			score -= invalidPenalty
+		}
+	}
+	return
+}
+
+// DetectEncoding guesses the encoding of the beginning of a log by the BOM,
+// the zeros of UTF-16, and the byte patterns of GBK, Shift-JIS and Windows-1251.
+// It returns EncodingUTF8 if the sample is valid UTF-8 or no encoding fits
+func DetectEncoding(sample []byte) string {
+	switch {
+	case bytes.HasPrefix(sample, []byte{0xef, 0xbb, 0xbf}):
+		return EncodingUTF8
+	case bytes.HasPrefix(sample, []byte{0xff, 0xfe}):
+		return EncodingUTF16LE
+	case bytes.HasPrefix(sample, []byte{0xfe, 0xff}):
+		return EncodingUTF16BE
+	}
+	if order := utf16Order(sample); order != "" {
+		return order
+	}
+	if validUTF8(sample) {
+		return EncodingUTF8
+	}
+	best, bestScore := EncodingUTF8, 0
+	for _, c := range []struct {
+		name  string
+		score func([]byte) int
+	}{
+		{EncodingGBK, gbkScore},
+		{EncodingShiftJIS, shiftJISScore},
+		{EncodingCP1251, cp1251Score},
+	} {
+		if score := c.score(sample); score > bestScore {
+			best, bestScore = c.name, score
+		}
+	}
+	return best
+}
+
+// DecodedReader reads a log as UTF-8 text
+type DecodedReader struct {
+	io.Reader
+	// Encoding is the detected or the given encoding of the log.
+	// If the detected beginning is pure ASCII, it's detected again once the first non-ASCII byte is read
+	Encoding string
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+// readSample fills the sample from r until it's full or r ends, rest is the reader of the following data,
+// which returns the error of r if it's not io.EOF, and more reports whether rest is r
+func readSample(r io.Reader, sample []byte) (n int, rest io.Reader, more bool) {
+	n, err := io.ReadFull(r, sample)
+	switch err {
+	case nil:
+		return n, r, true
+	case io.EOF, io.ErrUnexpectedEOF:
+		return n, bytes.NewReader(nil), false
+	}
+	return n, errReader{err}, false
+}
+
+func isASCII(sample []byte) bool {
+	for _, b := range sample {
+		if b >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeSample returns the reader decoding the sample and the rest as the encoding
+func decodeSample(name string, sample []byte, rest io.Reader) io.Reader {
+	if name == EncodingUTF8 {
+		sample = bytes.TrimPrefix(sample, []byte{0xef, 0xbb, 0xbf})
+		return io.MultiReader(bytes.NewReader(sample), rest)
+	}
+	e, _ := lookupEncoding(name)
+	return transform.NewReader(io.MultiReader(bytes.NewReader(sample), rest), unicode.BOMOverride(e.NewDecoder()))
+}
+
+// lateDetector passes the ASCII text through, and detects the encoding from the first non-ASCII byte.
+// All the encodings except UTF-16 are compatible with ASCII, and UTF-16 is already detected by the zeros
+type lateDetector struct {
+	r  io.Reader
+	dr *DecodedReader
+	// decoded is set once the encoding is detected
+	decoded io.Reader
+}
+
+func (d *lateDetector) Read(buf []byte) (n int, err error) {
+	if d.decoded != nil {
+		return d.decoded.Read(buf)
+	}
+	n, err = d.r.Read(buf)
+	i := slices.IndexFunc(buf[:n], func(b byte) bool { return b >= utf8.RuneSelf })
+	if i < 0 {
+		return
+	}
+	sample := make([]byte, max(sniffSize, n-i))
+	m := copy(sample, buf[i:n])
+	var rest io.Reader = d.r
+	switch {
+	case err == io.EOF:
+		rest = bytes.NewReader(nil)
+	case err != nil:
+		rest = errReader{err}
+	case m < sniffSize:
+		var k int
+		k, rest, _ = readSample(d.r, sample[m:sniffSize])
+		m += k
+	}
+	sample = sample[:m]
+	d.dr.Encoding = DetectEncoding(sample)
+	d.decoded = decodeSample(d.dr.Encoding, sample, rest)
+	if i == 0 {
+		return d.decoded.Read(buf)
+	}
+	return i, nil
+}
+
+// NewDecodedReader decodes the log from the encoding, if the encoding is empty or "auto",
+// it's detected by the first sniffSize bytes of the log, so it waits until they are read or the log ends.
+// The byte order marks are removed, and the lines are kept as-is
+func NewDecodedReader(r io.Reader, enc string) (dr *DecodedReader, err error) {
+	if enc != "" && enc != "auto" {
+		name := NormalizeEncoding(enc)
+		if name == "" {
+			return nil, fmt.Errorf("Unsupported encoding %q", enc)
+		}
+		e, _ := lookupEncoding(name)
+		return &DecodedReader{
+			Reader:   transform.NewReader(r, unicode.BOMOverride(e.NewDecoder())),
+			Encoding: name,
+		}, nil
+	}
+	buf := make([]byte, sniffSize)
+	n, rest, more := readSample(r, buf)
+	sample := buf[:n]
+	dr = &DecodedReader{
+		Encoding: DetectEncoding(sample),
+	}
+	if more && isASCII(sample) {
+		// the non-ASCII text may appear later, e.g. the player names and the paths
+		dr.Reader = io.MultiReader(bytes.NewReader(sample), &lateDetector{r: r, dr: dr})
+		return
+	}
+	dr.Reader = decodeSample(dr.Encoding, sample, rest)
+	return
+}
+
+// decodedReader is implemented by *DecodedReader,
+// and by the types embedding it, e.g. a DecodedReader with the io.Closer of the file
+type decodedReader interface {
+	io.Reader
+	decoded()
+}
+
+func (*DecodedReader) decoded() {}
+
+// decodeReader detects the encoding of the log, unless it's already decoded
+func decodeReader(r io.Reader) io.Reader {
+	if dr, ok := r.(decodedReader); ok {
+		return dr
+	}
+	dr, _ := NewDecodedReader(r, "")
+	return dr
+}
+
+// ansiEscapeRe matches the ANSI escape sequences of colors and cursor movements in console captures
+var ansiEscapeRe = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]`)
+
+// StripANSI removes the ANSI color codes of the text
+func StripANSI(text string) string {
+	if strings.IndexByte(text, '\x1b') < 0 {
+		return text
+	}
+	return ansiEscapeRe.ReplaceAllString(text, "")
+}
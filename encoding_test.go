@@ -0,0 +1,131 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"io"
+	"strings"
+	"testing/iotest"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+const encodedLog = "[00:00:00] [main/INFO]: %s\n" +
+	"java.lang.IllegalStateException: %s\n" +
+	"\tat com.example.mod.Mob.tick(Mob.java:1)\n"
+
+func encodeLog(t *testing.T, enc encoding.Encoding, text string) string {
+	t.Helper()
+	log := strings.ReplaceAll(encodedLog, "%s", text)
+	encoded, err := enc.NewEncoder().String(log)
+	if err != nil {
+		t.Fatalf("Cannot encode %q: %v", text, err)
+	}
+	return encoded
+}
+
+var encodingTests = []struct {
+	name string
+	enc  encoding.Encoding
+	text string
+}{
+	{EncodingUTF8, encoding.Nop, "游戏崩溃了"},
+	{EncodingGBK, simplifiedchinese.GBK, "游戏崩溃了，无法加载模组"},
+	{EncodingShiftJIS, japanese.ShiftJIS, "ゲームがクラッシュしました"},
+	{EncodingCP1251, charmap.Windows1251, "Игра вылетела при загрузке"},
+	{EncodingUTF16LE, unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), "游戏崩溃了"},
+	{EncodingUTF16LE, unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), "Game crashed"},
+	{EncodingUTF16BE, unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), "Game crashed"},
+}
+
+func TestDetectEncoding(t *testing.T) {
+	for _, tt := range encodingTests {
+		if got := DetectEncoding([]byte(encodeLog(t, tt.enc, tt.text))); got != tt.name {
+			t.Errorf("Expect %q detected as %s, got %s", tt.text, tt.name, got)
+		}
+	}
+}
+
+func TestScanJavaErrorsEncoded(t *testing.T) {
+	for _, tt := range encodingTests {
+		errs, err := ScanJavaErrors(strings.NewReader(encodeLog(t, tt.enc, tt.text)))
+		if err != nil {
+			t.Fatalf("ScanJavaErrors: %v", err)
+		}
+		if len(errs) != 1 {
+			t.Fatalf("Expect 1 error in the %s log, got %d", tt.name, len(errs))
+		}
+		if errs[0].Message != tt.text || errs[0].LineNo != 2 {
+			t.Errorf("Expect %q at line 2, got %q at line %d", tt.text, errs[0].Message, errs[0].LineNo)
+		}
+	}
+}
+
+func TestNewDecodedReader(t *testing.T) {
+	// the explicit encoding accepts the aliases
+	log := encodeLog(t, simplifiedchinese.GBK, "模组加载失败")
+	r, err := NewDecodedReader(strings.NewReader(log), "GB2312")
+	if err != nil {
+		t.Fatalf("NewDecodedReader: %v", err)
+	}
+	if r.Encoding != EncodingGBK {
+		t.Errorf("Expect the encoding %s, got %s", EncodingGBK, r.Encoding)
+	}
+	text, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if expect := strings.ReplaceAll(encodedLog, "%s", "模组加载失败"); string(text) != expect {
+		t.Errorf("Expect the decoded log %q, got %q", expect, text)
+	}
+	if _, err := NewDecodedReader(strings.NewReader(log), "klingon"); err == nil {
+		t.Errorf("Expect an error for the unsupported encoding")
+	}
+}
+
+func TestNewDecodedReaderDetect(t *testing.T) {
+	padding := strings.Repeat("[00:00:00] [main/INFO]: Loading\n", 4096)
+	for _, tt := range []struct {
+		name string
+		log  string
+	}{
+		// the sample is filled from the short reads
+		{"short reads", encodeLog(t, simplifiedchinese.GBK, "游戏崩溃了，无法加载模组")},
+		// the non-ASCII text is after the pure ASCII sample
+		{"late text", padding + encodeLog(t, simplifiedchinese.GBK, "游戏崩溃了，无法加载模组")},
+	} {
+		r, err := NewDecodedReader(iotest.OneByteReader(strings.NewReader(tt.log)), "auto")
+		if err != nil {
+			t.Fatalf("NewDecodedReader: %v", err)
+		}
+		text, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("ReadAll: %v", err)
+		}
+		if !strings.HasSuffix(string(text), strings.ReplaceAll(encodedLog, "%s", "游戏崩溃了，无法加载模组")) {
+			t.Errorf("%s: Expect the log is decoded from GBK, got %q", tt.name, text[max(0, len(text)-200):])
+		}
+		if r.Encoding != EncodingGBK {
+			t.Errorf("%s: Expect the encoding %s, got %s", tt.name, EncodingGBK, r.Encoding)
+		}
+	}
+}
+
+func TestStripANSI(t *testing.T) {
+	errs, err := ScanJavaErrors(strings.NewReader("\x1b[31mjava.lang.IllegalStateException: Bad state\x1b[0m\n" +
+		"\x1b[31m\tat com.example.mod.Mob.tick(Mob.java:1)\x1b[0m\n"))
+	if err != nil {
+		t.Fatalf("ScanJavaErrors: %v", err)
+	}
+	if len(errs) != 1 || errs[0].Class != "java.lang.IllegalStateException" || errs[0].Message != "Bad state" || len(errs[0].Stacktrace) != 1 {
+		t.Errorf("Expect the colored error parsed, got %v", errs)
+	}
+	if got := StripANSI("\x1b[1;32m[INFO]\x1b[m Done\x1b[K"); got != "[INFO] Done" {
+		t.Errorf("Expect the ANSI codes stripped, got %q", got)
+	}
+}
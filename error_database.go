@@ -1,19 +1,103 @@
 package mcla
 
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+// ErrEntryNotFound should be wrapped by the errors of ErrorDB when the entry does not exist
+var ErrEntryNotFound = errors.New("Entry not found")
+
 type ErrorDesc struct {
-	Error     string         `json:"error"`
-	Message   string         `json:"message"`
+	Error   string `json:"error"`
+	Message string `json:"message"`
+	// Pattern is a regexp used instead of Message if it's not empty,
+	// the captured groups can be used in the solution's placeholders
+	Pattern   string         `json:"pattern,omitempty"`
 	Solutions []int          `json:"solutions"`
 	Data      map[string]any `json:"data,omitempty"`
+
+	// ID is the entry's ID in its source database, 0 means unknown
+	ID int `json:"id,omitempty"`
+	// Source is the name of the database layer which the entry comes from
+	Source string `json:"source,omitempty"`
+	// Overrides lists the entries of lower priority layers that this entry replaces, in "<source>:<id>" format.
+	// An entry with empty Error and Message only disables the listed entries
+	Overrides []string `json:"overrides,omitempty"`
+	// Samples are paths of example logs relative to the database root,
+	// this entry is expected to be the top match of them
+	Samples []string `json:"samples,omitempty"`
 }
 
 type SolutionDesc struct {
 	Tags        []string `json:"tags"`
 	Description string   `json:"description"`
 	LinkTo      string   `json:"link_to"`
+	// Descriptions are the translations of Description, keyed by locale, e.g. "zh-CN"
+	Descriptions map[string]string `json:"descriptions,omitempty"`
 }
 
 type ErrorDB interface {
 	ForEachErrors(callback func(*ErrorDesc) error) (err error)
 	GetSolution(id int) (sol *SolutionDesc, err error)
 }
+
+// Similarity reports how close two entries are, from 0 to 1
+func (e *ErrorDesc) Similarity(other *ErrorDesc) float32 {
+	if e.Error != other.Error {
+		_, ecls := rsplit(e.Error, '.')
+		_, ecls2 := rsplit(other.Error, '.')
+		if ecls != ecls2 {
+			return 0
+		}
+	}
+	if e.Message == other.Message {
+		return 1
+	}
+	return lcsPercent(([]rune)(e.Message), ([]rune)(other.Message))
+}
+
+// maxPatternCache limits the compiled patterns, the cache is reset when it's full
+const maxPatternCache = 4096
+
+var patternCache struct {
+	sync.RWMutex
+	m map[string]*regexp.Regexp
+}
+
+// CompilePattern compiles ErrorDesc.Pattern, the result is cached
+func (e *ErrorDesc) CompilePattern() (re *regexp.Regexp, err error) {
+	patternCache.RLock()
+	re = patternCache.m[e.Pattern]
+	patternCache.RUnlock()
+	if re != nil {
+		return
+	}
+	if re, err = regexp.Compile(e.Pattern); err != nil {
+		return
+	}
+	patternCache.Lock()
+	defer patternCache.Unlock()
+	if patternCache.m == nil || len(patternCache.m) >= maxPatternCache {
+		patternCache.m = make(map[string]*regexp.Regexp)
+	}
+	patternCache.m[e.Pattern] = re
+	return
+}
+
+// capturedData maps the groups by their names, and by their indexes if they're unnamed
+func capturedData(re *regexp.Regexp, groups []string) (data map[string]any) {
+	if len(groups) <= 1 {
+		return nil
+	}
+	data = make(map[string]any, len(groups)-1)
+	for i, name := range re.SubexpNames()[1:] {
+		if name == "" {
+			name = strconv.Itoa(i + 1)
+		}
+		data[name] = groups[i+1]
+	}
+	return
+}
@@ -0,0 +1,73 @@
+package ghdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/GlobeMC/mcla"
+)
+
+func readVersionFile(dir string) (v versionData, err error) {
+	buf, err := os.ReadFile(filepath.Join(dir, "version.json"))
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(buf, &v); err != nil {
+		return
+	}
+	if v.Major != syntaxVersion {
+		err = &UnsupportSyntaxErr{v.Major}
+	}
+	return
+}
+
+func writeJSONFile(name string, v any, exclusive bool) (err error) {
+	buf, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return
+	}
+	buf = append(buf, '\n')
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if exclusive {
+		flags |= os.O_EXCL
+	}
+	fd, err := os.OpenFile(name, flags, 0644)
+	if err != nil {
+		return
+	}
+	if _, err = fd.Write(buf); err != nil {
+		fd.Close()
+		return
+	}
+	return fd.Close()
+}
+
+// AddErrorDesc writes the entry as errors/<id>.json of the database directory,
+// where id is the next errorIncId, and updates version.json
+func AddErrorDesc(dir string, desc *mcla.ErrorDesc) (id int, err error) {
+	version, err := readVersionFile(dir)
+	if err != nil {
+		return
+	}
+	id = version.ErrorIncId + 1
+	entry := *desc
+	entry.ID, entry.Source = 0, ""
+	if entry.Solutions == nil {
+		entry.Solutions = []int{}
+	}
+	errorsDir := filepath.Join(dir, "errors")
+	if err = os.MkdirAll(errorsDir, 0755); err != nil {
+		return
+	}
+	if err = writeJSONFile(filepath.Join(errorsDir, fmt.Sprintf("%d.json", id)), &entry, true); err != nil {
+		return
+	}
+	version.ErrorIncId = id
+	version.Patch++
+	if err = writeJSONFile(filepath.Join(dir, "version.json"), &version, false); err != nil {
+		return
+	}
+	return
+}
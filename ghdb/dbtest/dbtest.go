@@ -0,0 +1,33 @@
+// Package dbtest helps to run the sample logs of a MCLA database in Go tests
+package dbtest
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+
+	"github.com/GlobeMC/mcla/ghdb"
+)
+
+// RunSamples runs each sample of the database as a subtest.
+// If threshold is zero, ghdb.DefaultSampleThreshold is used
+func RunSamples(t *testing.T, fsys fs.FS, threshold float32) {
+	t.Helper()
+	if threshold == 0 {
+		threshold = ghdb.DefaultSampleThreshold
+	}
+	results, err := ghdb.CheckSamples(context.Background(), fsys, threshold)
+	if err != nil {
+		t.Fatalf("Cannot check samples: %v", err)
+	}
+	if len(results) == 0 {
+		t.Logf("No sample was found")
+	}
+	for _, res := range results {
+		t.Run(res.Sample, func(t *testing.T) {
+			if !res.Passed {
+				t.Error(res.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package ghdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/GlobeMC/mcla"
+)
+
+var (
+	ErrNotFound   = fmt.Errorf("MCLA-DB %w", mcla.ErrEntryNotFound)
+	ErrEmptyEntry = errors.New("MCLA-DB entry is empty")
+)
+
+type HTTPStatusErr struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *HTTPStatusErr) Error() string {
+	return fmt.Sprintf("HTTP status code error: %d when getting %q", e.StatusCode, e.URL)
+}
+
+func (e *HTTPStatusErr) Unwrap() error {
+	if e.StatusCode == 404 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type FetchRequest struct {
+	// Context cancels the request and its retries, nil means it's never cancelled
+	Context context.Context
+	Path    string
+	// Validators of the cached copy, empty if there is no cached copy.
+	// They should be sent as If-None-Match and If-Modified-Since
+	ETag         string
+	LastModified string
+}
+
+type FetchResponse struct {
+	// Body is nil when NotModified is true
+	Body         io.ReadCloser
+	ETag         string
+	LastModified string
+	NotModified  bool
+}
+
+// Fetcher should return an error wraps ErrNotFound if the path does not exist
+type Fetcher interface {
+	Fetch(req *FetchRequest) (*FetchResponse, error)
+}
+
+type FetcherFunc func(req *FetchRequest) (*FetchResponse, error)
+
+var _ Fetcher = (FetcherFunc)(nil)
+
+func (f FetcherFunc) Fetch(req *FetchRequest) (*FetchResponse, error) {
+	return f(req)
+}
+
+// entryMeta is the validators of a cached entry
+type entryMeta struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+func (m entryMeta) isZero() bool {
+	return m == (entryMeta{})
+}
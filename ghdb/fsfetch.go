@@ -0,0 +1,38 @@
+package ghdb
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// FSFetcher fetches the database from a local file system, e.g. os.DirFS
+type FSFetcher struct {
+	FS fs.FS
+}
+
+var _ Fetcher = FSFetcher{}
+
+func (f FSFetcher) Fetch(req *FetchRequest) (res *FetchResponse, err error) {
+	fd, err := f.FS.Open(req.Path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, req.Path)
+		}
+		return
+	}
+	stat, err := fd.Stat()
+	if err != nil {
+		fd.Close()
+		return
+	}
+	etag := fmt.Sprintf(`"%x-%x"`, stat.ModTime().UnixNano(), stat.Size())
+	if req.ETag == etag {
+		fd.Close()
+		return &FetchResponse{NotModified: true}, nil
+	}
+	return &FetchResponse{
+		Body: fd,
+		ETag: etag,
+	}, nil
+}
@@ -4,6 +4,7 @@ package ghdb
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"path"
@@ -33,33 +34,91 @@ type versionData struct {
 }
 
 type ErrDB struct {
-	Fetch func(path string) (io.ReadCloser, error)
-	Cache Cache
+	Fetcher Fetcher
+	Cache   Cache
+	// Context is passed to the fetcher, the pending requests are cancelled once it's done.
+	// nil means the requests are never cancelled
+	Context context.Context
+	// RevalidateInterval is how often the cached entries are revalidated while version.json is not modified,
+	// since an entry may be edited without a version bump. 0 means defaultRevalidateInterval
+	RevalidateInterval time.Duration
 
-	checking      atomic.Bool
-	cachedVersion versionData
-	lastCheck     time.Time
+	checking       atomic.Bool
+	cachedVersion  versionData
+	lastCheck      time.Time
+	lastRevalidate time.Time
 }
 
+const defaultRevalidateInterval = 10 * time.Minute
+
 var _ mcla.ErrorDB = (*ErrDB)(nil)
+var _ mcla.SolutionIterator = (*ErrDB)(nil)
+
+const (
+	versionCacheKey = "version"
+	metaCacheKey    = "meta."
+)
 
-func (db *ErrDB) fetch(subpaths ...string) (io.ReadCloser, error) {
-	return db.Fetch(path.Join(subpaths...))
+func (db *ErrDB) getMeta(cacheKey string) (meta entryMeta) {
+	if buf := db.Cache.Get(metaCacheKey + cacheKey); buf != "" {
+		json.Unmarshal(([]byte)(buf), &meta)
+	}
+	return
 }
 
-func (db *ErrDB) fetchGhDBVersion() (v versionData, err error) {
-	var res io.ReadCloser
-	if res, err = db.fetch("version.json"); err != nil {
+func (db *ErrDB) setMeta(cacheKey string, meta entryMeta) {
+	if meta.isZero() {
+		db.Cache.Remove(metaCacheKey + cacheKey)
 		return
 	}
-	defer res.Close()
-	if err = json.NewDecoder(res).Decode(&v); err != nil {
+	buf, _ := json.Marshal(meta)
+	db.Cache.Set(metaCacheKey+cacheKey, (string)(buf))
+}
+
+// fetch requests the file at the given path,
+// if meta is not zero, a conditional request will be sent,
+// and body will be nil if the file is not modified
+func (db *ErrDB) fetch(meta entryMeta, subpaths ...string) (body []byte, newMeta entryMeta, err error) {
+	res, err := db.Fetcher.Fetch(&FetchRequest{
+		Context:      db.Context,
+		Path:         path.Join(subpaths...),
+		ETag:         meta.ETag,
+		LastModified: meta.LastModified,
+	})
+	if err != nil {
 		return
 	}
-	if v.Major != syntaxVersion {
-		err = &UnsupportSyntaxErr{v.Major}
+	if res.NotModified {
+		return nil, meta, nil
+	}
+	defer res.Body.Close()
+	if body, err = io.ReadAll(res.Body); err != nil {
 		return
 	}
+	newMeta = entryMeta{
+		ETag:         res.ETag,
+		LastModified: res.LastModified,
+	}
+	return
+}
+
+// fetchGhDBVersion returns nil if version.json is not modified since last check.
+// The returned meta is not saved, so version.json is fetched again until the cache is refreshed
+func (db *ErrDB) fetchGhDBVersion() (v *versionData, meta entryMeta, err error) {
+	if db.cachedVersion != (versionData{}) {
+		meta = db.getMeta(versionCacheKey)
+	}
+	buf, meta, err := db.fetch(meta, "version.json")
+	if err != nil || buf == nil {
+		return
+	}
+	v = new(versionData)
+	if err = json.Unmarshal(buf, v); err != nil {
+		return nil, meta, err
+	}
+	if v.Major != syntaxVersion {
+		return nil, meta, &UnsupportSyntaxErr{v.Major}
+	}
 	return
 }
 
@@ -78,74 +137,180 @@ func (db *ErrDB) checkUpdate() error {
 
 func (db *ErrDB) RefreshCache() (err error) {
 	if db.cachedVersion == (versionData{}) {
-		version := db.Cache.Get("version")
+		version := db.Cache.Get(versionCacheKey)
 		json.Unmarshal(([]byte)(version), &db.cachedVersion)
 	}
-	newVersion, err := db.fetchGhDBVersion()
+	newVersion, versionMeta, err := db.fetchGhDBVersion()
 	if err != nil {
 		return
 	}
-	if newVersion.Major != db.cachedVersion.Major || newVersion.Minor != db.cachedVersion.Minor {
-		db.cachedVersion = newVersion
-		db.Cache.Clear()
-		var wg sync.WaitGroup
-		wg.Add(newVersion.ErrorIncId)
-		for i := 1; i <= newVersion.ErrorIncId; i++ {
-			go func(i int) {
-				defer wg.Done()
-				db.GetErrorDesc(i) // refresh cache
-			}(i)
-		}
-		wg.Add(newVersion.SolutionIncId)
-		for i := 1; i <= newVersion.SolutionIncId; i++ {
-			go func(i int) {
-				defer wg.Done()
-				db.GetSolution(i) // refresh cache
-			}(i)
+	if newVersion == nil { // not modified
+		interval := db.RevalidateInterval
+		if interval <= 0 {
+			interval = defaultRevalidateInterval
 		}
-		wg.Wait()
-	} else if newVersion.Patch != db.cachedVersion.Patch {
-		var wg sync.WaitGroup
-		wg.Add(newVersion.ErrorIncId - db.cachedVersion.ErrorIncId)
-		for i := db.cachedVersion.ErrorIncId + 1; i <= newVersion.ErrorIncId; i++ {
-			go func(i int) {
-				defer wg.Done()
-				db.GetErrorDesc(i) // refresh cache
-			}(i)
+		if time.Since(db.lastRevalidate) >= interval {
+			if err = db.revalidateEntries(db.cachedVersion); err != nil {
+				return
+			}
 		}
-		wg.Wait()
-		db.cachedVersion.ErrorIncId = newVersion.ErrorIncId
-		wg.Add(newVersion.SolutionIncId - db.cachedVersion.SolutionIncId)
-		for i := db.cachedVersion.SolutionIncId + 1; i <= newVersion.SolutionIncId; i++ {
-			go func(i int) {
-				defer wg.Done()
-				db.GetSolution(i) // refresh cache
-			}(i)
+		db.lastCheck = time.Now()
+		return
+	}
+	if newVersion.Major != db.cachedVersion.Major || newVersion.Minor != db.cachedVersion.Minor {
+		db.Cache.Clear()
+		// the entries are fetched again when they are used, so the failures are not returned
+		forEachID(newVersion.ErrorIncId, func(i int) error {
+			db.GetErrorDesc(i) // refresh cache
+			return nil
+		})
+		forEachID(newVersion.SolutionIncId, func(i int) error {
+			db.GetSolution(i) // refresh cache
+			return nil
+		})
+	} else {
+		// entries may be edited within a patch, so re-validate each of them
+		if err = db.revalidateEntries(versionData{
+			ErrorIncId:    max(newVersion.ErrorIncId, db.cachedVersion.ErrorIncId),
+			SolutionIncId: max(newVersion.SolutionIncId, db.cachedVersion.SolutionIncId),
+		}); err != nil {
+			// the version is not saved, so the entries are revalidated again in the next check
+			return
 		}
-		wg.Wait()
-		db.cachedVersion.SolutionIncId = newVersion.SolutionIncId
-		db.cachedVersion.Patch = newVersion.Patch
 	}
+	db.cachedVersion = *newVersion
+	if buf, err := json.Marshal(newVersion); err == nil {
+		db.Cache.Set(versionCacheKey, (string)(buf))
+	}
+	db.setMeta(versionCacheKey, versionMeta)
 
 	db.lastCheck = time.Now()
+	db.lastRevalidate = db.lastCheck
 	return
 }
 
-func (db *ErrDB) GetErrorDesc(id int) (desc *mcla.ErrorDesc, err error) {
-	cacheKey := fmt.Sprintf("error.%d", id)
-	buf := db.Cache.GetOrSet(cacheKey, func() string {
-		var res io.ReadCloser
-		if res, err = db.fetch("errors", fmt.Sprintf("%d.json", id)); err != nil {
-			return ""
+// revalidateEntries revalidates the cached entries up to the IDs of the version,
+// lastRevalidate is updated only when all of them are revalidated
+func (db *ErrDB) revalidateEntries(v versionData) error {
+	err := errors.Join(
+		forEachID(v.ErrorIncId, func(i int) (err error) {
+			_, err = db.revalidate(errorCacheKey(i), "errors", fmt.Sprintf("%d.json", i))
+			return
+		}),
+		forEachID(v.SolutionIncId, func(i int) (err error) {
+			_, err = db.revalidate(solutionCacheKey(i), "solutions", fmt.Sprintf("%d.json", i))
+			return
+		}),
+	)
+	if err != nil {
+		return err
+	}
+	db.lastRevalidate = time.Now()
+	return nil
+}
+
+// refreshWorkers limits the concurrent requests when refreshing the cache
+const refreshWorkers = 8
+
+// forEachID calls fn with the IDs from 1 to n in at most refreshWorkers goroutines,
+// and returns the joined errors of the calls
+func forEachID(n int, fn func(id int) error) error {
+	ids := make(chan int)
+	var (
+		wg   sync.WaitGroup
+		mux  sync.Mutex
+		errs []error
+	)
+	for range min(n, refreshWorkers) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range ids {
+				if err := fn(id); err != nil {
+					mux.Lock()
+					errs = append(errs, err)
+					mux.Unlock()
+				}
+			}
+		}()
+	}
+	for i := 1; i <= n; i++ {
+		ids <- i
+	}
+	close(ids)
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// revalidate sends a conditional request for the cached entry and updates the cache if it's changed.
+// The entries not cached are skipped, and the entries without validators are removed from the cache,
+// they will be fetched when they are used
+func (db *ErrDB) revalidate(cacheKey string, subpaths ...string) (changed bool, err error) {
+	if db.Cache.Get(cacheKey) == "" {
+		return false, nil
+	}
+	meta := db.getMeta(cacheKey)
+	if meta.isZero() {
+		// the fetcher does not support conditional requests
+		db.Cache.Remove(cacheKey)
+		return true, nil
+	}
+	buf, meta, err := db.fetch(meta, subpaths...)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			db.Cache.Remove(cacheKey)
+			db.setMeta(cacheKey, entryMeta{})
+			return true, nil
 		}
-		var buf []byte
-		buf, err = io.ReadAll(res)
-		res.Close()
-		if err != nil {
+		return
+	}
+	if buf == nil {
+		return false, nil
+	}
+	db.Cache.Set(cacheKey, (string)(buf))
+	db.setMeta(cacheKey, meta)
+	return true, nil
+}
+
+// getEntry returns the cached entry, or fetches it if it's not cached
+func (db *ErrDB) getEntry(cacheKey string, subpaths ...string) (buf string, err error) {
+	var (
+		fetched bool
+		meta    entryMeta
+	)
+	// the setter must not access the cache, as it may be called with the cache locked
+	buf = db.Cache.GetOrSet(cacheKey, func() string {
+		var body []byte
+		if body, meta, err = db.fetch(entryMeta{}, subpaths...); err != nil {
 			return ""
 		}
-		return (string)(buf)
+		fetched = true
+		return (string)(body)
 	})
+	if fetched {
+		db.setMeta(cacheKey, meta)
+	}
+	if err == nil && buf == "" {
+		// the fetch was failed in another goroutine
+		err = ErrEmptyEntry
+	}
+	if err != nil {
+		db.Cache.Remove(cacheKey) // do not cache failures
+	}
+	return
+}
+
+func errorCacheKey(id int) string {
+	return fmt.Sprintf("error.%d", id)
+}
+
+func solutionCacheKey(id int) string {
+	return fmt.Sprintf("solution.%d", id)
+}
+
+func (db *ErrDB) GetErrorDesc(id int) (desc *mcla.ErrorDesc, err error) {
+	cacheKey := errorCacheKey(id)
+	buf, err := db.getEntry(cacheKey, "errors", fmt.Sprintf("%d.json", id))
 	if err != nil {
 		return
 	}
@@ -155,6 +320,7 @@ func (db *ErrDB) GetErrorDesc(id int) (desc *mcla.ErrorDesc, err error) {
 		desc = nil
 		return
 	}
+	desc.ID = id
 	return
 }
 
@@ -162,22 +328,28 @@ func (db *ErrDB) ForEachErrors(callback func(*mcla.ErrorDesc) error) (err error)
 	db.checkUpdate()
 
 	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
 	resCh := make(chan *mcla.ErrorDesc, 2)
 
 	for i := 1; i <= db.cachedVersion.ErrorIncId; i++ {
 		go func(i int) {
-			println("getting error", i)
 			desc, err := db.GetErrorDesc(i)
-			if err != nil {
+			if err != nil && !errors.Is(err, ErrNotFound) { // removed entries are skipped
 				cancel(err)
 				return
 			}
-			resCh <- desc
+			select {
+			case resCh <- desc:
+			case <-ctx.Done():
+			}
 		}(i)
 	}
 	for i := 1; i <= db.cachedVersion.ErrorIncId; i++ {
 		select {
 		case desc := <-resCh:
+			if desc == nil {
+				continue
+			}
 			if err = callback(desc); err != nil {
 				return
 			}
@@ -189,20 +361,8 @@ func (db *ErrDB) ForEachErrors(callback func(*mcla.ErrorDesc) error) (err error)
 }
 
 func (db *ErrDB) GetSolution(id int) (sol *mcla.SolutionDesc, err error) {
-	cacheKey := fmt.Sprintf("solution.%d", id)
-	buf := db.Cache.GetOrSet(cacheKey, func() string {
-		var res io.ReadCloser
-		if res, err = db.fetch("solutions", fmt.Sprintf("%d.json", id)); err != nil {
-			return ""
-		}
-		var buf []byte
-		buf, err = io.ReadAll(res)
-		res.Close()
-		if err != nil {
-			return ""
-		}
-		return (string)(buf)
-	})
+	cacheKey := solutionCacheKey(id)
+	buf, err := db.getEntry(cacheKey, "solutions", fmt.Sprintf("%d.json", id))
 	if err != nil {
 		return
 	}
@@ -214,3 +374,22 @@ func (db *ErrDB) GetSolution(id int) (sol *mcla.SolutionDesc, err error) {
 	}
 	return
 }
+
+// ForEachSolutions iterates the solutions in ID order, removed solutions are skipped
+func (db *ErrDB) ForEachSolutions(callback func(id int, sol *mcla.SolutionDesc) error) (err error) {
+	db.checkUpdate()
+
+	for i := 1; i <= db.cachedVersion.SolutionIncId; i++ {
+		sol, err := db.GetSolution(i)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			return err
+		}
+		if err = callback(i, sol); err != nil {
+			return err
+		}
+	}
+	return
+}
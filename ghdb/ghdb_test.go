@@ -0,0 +1,286 @@
+package ghdb_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/GlobeMC/mcla/ghdb"
+)
+
+type fakeFile struct {
+	body string
+	etag string
+}
+
+// fakeFetcher serves the files in memory, and records the requests
+type fakeFetcher struct {
+	mux      sync.Mutex
+	files    map[string]fakeFile
+	failures map[string]error
+	requests []FetchRequest
+}
+
+func newFakeFetcher() *fakeFetcher {
+	return &fakeFetcher{
+		files:    make(map[string]fakeFile),
+		failures: make(map[string]error),
+	}
+}
+
+func (f *fakeFetcher) set(path, body, etag string) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.files[path] = fakeFile{body, etag}
+}
+
+// fail makes the requests to the path fail with err, nil means they succeed again
+func (f *fakeFetcher) fail(path string, err error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	if err == nil {
+		delete(f.failures, path)
+	} else {
+		f.failures[path] = err
+	}
+}
+
+func (f *fakeFetcher) setVersion(minor, patch int, etag string) {
+	f.set("version.json", fmt.Sprintf(`{"major":0,"minor":%d,"patch":%d,"errorIncId":2,"solutionIncId":1}`, minor, patch), etag)
+}
+
+// requestsTo returns the recorded requests to the path
+func (f *fakeFetcher) requestsTo(path string) (reqs []FetchRequest) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	for _, req := range f.requests {
+		if req.Path == path {
+			reqs = append(reqs, req)
+		}
+	}
+	return
+}
+
+func (f *fakeFetcher) reset() {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.requests = nil
+}
+
+func (f *fakeFetcher) Fetch(req *FetchRequest) (*FetchResponse, error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.requests = append(f.requests, *req)
+	if err := f.failures[req.Path]; err != nil {
+		return nil, err
+	}
+	file, ok := f.files[req.Path]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, req.Path)
+	}
+	if file.etag != "" && req.ETag == file.etag {
+		return &FetchResponse{NotModified: true}, nil
+	}
+	return &FetchResponse{
+		Body: io.NopCloser(strings.NewReader(file.body)),
+		ETag: file.etag,
+	}, nil
+}
+
+func errorJSON(message string) string {
+	return fmt.Sprintf(`{"error":"java.lang.RuntimeException","message":%q,"solutions":[1]}`, message)
+}
+
+func newFakeDB(t *testing.T, etag bool) (*ErrDB, *fakeFetcher) {
+	t.Helper()
+	f := newFakeFetcher()
+	tag := func(s string) string {
+		if etag {
+			return s
+		}
+		return ""
+	}
+	f.setVersion(1, 0, tag(`"v1"`))
+	f.set("errors/1.json", errorJSON("first"), tag(`"e1"`))
+	f.set("errors/2.json", errorJSON("second"), tag(`"e2"`))
+	f.set("solutions/1.json", `{"description":"solution"}`, tag(`"s1"`))
+	db := &ErrDB{
+		Fetcher: f,
+		Cache:   NewInMemoryCache(),
+	}
+	if err := db.RefreshCache(); err != nil {
+		t.Fatalf("RefreshCache: %v", err)
+	}
+	if _, err := db.GetErrorDesc(1); err != nil {
+		t.Fatalf("GetErrorDesc: %v", err)
+	}
+	f.reset()
+	return db, f
+}
+
+func TestRefreshCacheNotModified(t *testing.T) {
+	db, f := newFakeDB(t, true)
+	if err := db.RefreshCache(); err != nil {
+		t.Fatalf("RefreshCache: %v", err)
+	}
+	reqs := f.requestsTo("version.json")
+	if len(reqs) != 1 || reqs[0].ETag != `"v1"` {
+		t.Fatalf("Expect a conditional request of version.json, got %v", reqs)
+	}
+	if reqs := f.requestsTo("errors/1.json"); len(reqs) != 0 {
+		t.Errorf("Expect no request of the entries when the version is not modified, got %v", reqs)
+	}
+}
+
+func TestRefreshCachePatch(t *testing.T) {
+	db, f := newFakeDB(t, true)
+	f.setVersion(1, 1, `"v2"`)
+	f.set("errors/1.json", errorJSON("edited"), `"e1-2"`)
+	if err := db.RefreshCache(); err != nil {
+		t.Fatalf("RefreshCache: %v", err)
+	}
+	if reqs := f.requestsTo("errors/1.json"); len(reqs) != 1 || reqs[0].ETag != `"e1"` {
+		t.Errorf("Expect a conditional request of the cached entry, got %v", reqs)
+	}
+	f.reset()
+	desc, err := db.GetErrorDesc(1)
+	if err != nil {
+		t.Fatalf("GetErrorDesc: %v", err)
+	}
+	if desc.Message != "edited" {
+		t.Errorf("Expect the edited entry, got %q", desc.Message)
+	}
+	if reqs := f.requestsTo("errors/1.json"); len(reqs) != 0 {
+		t.Errorf("Expect the revalidated entry is cached, got %v", reqs)
+	}
+
+	// the entries which are not modified are kept
+	f.setVersion(1, 2, `"v3"`)
+	if err := db.RefreshCache(); err != nil {
+		t.Fatalf("RefreshCache: %v", err)
+	}
+	f.reset()
+	if desc, err = db.GetErrorDesc(1); err != nil {
+		t.Fatalf("GetErrorDesc: %v", err)
+	}
+	if reqs := f.requestsTo("errors/1.json"); desc.Message != "edited" || len(reqs) != 0 {
+		t.Errorf("Expect the cached entry after 304, got %q and %v", desc.Message, reqs)
+	}
+}
+
+func TestRefreshCacheWithoutValidators(t *testing.T) {
+	db, f := newFakeDB(t, false)
+	f.setVersion(1, 1, "")
+	f.set("errors/1.json", errorJSON("edited"), "")
+	if err := db.RefreshCache(); err != nil {
+		t.Fatalf("RefreshCache: %v", err)
+	}
+	if reqs := f.requestsTo("errors/1.json"); len(reqs) != 0 {
+		t.Errorf("Expect no revalidation without validators, got %v", reqs)
+	}
+	if reqs := f.requestsTo("errors/2.json"); len(reqs) != 0 {
+		t.Errorf("Expect the entries not cached are not fetched, got %v", reqs)
+	}
+	desc, err := db.GetErrorDesc(1)
+	if err != nil {
+		t.Fatalf("GetErrorDesc: %v", err)
+	}
+	if desc.Message != "edited" {
+		t.Errorf("Expect the entry is fetched again, got %q", desc.Message)
+	}
+}
+
+func TestRefreshCacheRemoved(t *testing.T) {
+	db, f := newFakeDB(t, true)
+	f.setVersion(1, 1, `"v2"`)
+	f.mux.Lock()
+	delete(f.files, "errors/1.json")
+	f.mux.Unlock()
+	if err := db.RefreshCache(); err != nil {
+		t.Fatalf("RefreshCache: %v", err)
+	}
+	if _, err := db.GetErrorDesc(1); err == nil {
+		t.Errorf("Expect the removed entry is not found")
+	}
+}
+
+func TestRefreshCacheMinor(t *testing.T) {
+	db, f := newFakeDB(t, true)
+	f.setVersion(2, 0, `"v2"`)
+	f.set("errors/1.json", errorJSON("edited"), `"e1-2"`)
+	if err := db.RefreshCache(); err != nil {
+		t.Fatalf("RefreshCache: %v", err)
+	}
+	for _, path := range []string{"errors/1.json", "errors/2.json", "solutions/1.json"} {
+		if reqs := f.requestsTo(path); len(reqs) != 1 || reqs[0].ETag != "" {
+			t.Errorf("Expect %s is fetched again without validators, got %v", path, reqs)
+		}
+	}
+	desc, err := db.GetErrorDesc(1)
+	if err != nil {
+		t.Fatalf("GetErrorDesc: %v", err)
+	}
+	if desc.Message != "edited" {
+		t.Errorf("Expect the new entry, got %q", desc.Message)
+	}
+}
+
+func TestRefreshCacheRevalidateInterval(t *testing.T) {
+	db, f := newFakeDB(t, true)
+	db.RevalidateInterval = time.Nanosecond
+	// edited without a version bump
+	f.set("errors/1.json", errorJSON("edited"), `"e1-2"`)
+	if err := db.RefreshCache(); err != nil {
+		t.Fatalf("RefreshCache: %v", err)
+	}
+	if reqs := f.requestsTo("errors/1.json"); len(reqs) != 1 || reqs[0].ETag != `"e1"` {
+		t.Errorf("Expect a conditional request of the cached entry, got %v", reqs)
+	}
+	desc, err := db.GetErrorDesc(1)
+	if err != nil {
+		t.Fatalf("GetErrorDesc: %v", err)
+	}
+	if desc.Message != "edited" {
+		t.Errorf("Expect the edited entry, got %q", desc.Message)
+	}
+
+	// not revalidated within the interval
+	db.RevalidateInterval = time.Hour
+	f.reset()
+	if err := db.RefreshCache(); err != nil {
+		t.Fatalf("RefreshCache: %v", err)
+	}
+	if reqs := f.requestsTo("errors/1.json"); len(reqs) != 0 {
+		t.Errorf("Expect no revalidation within the interval, got %v", reqs)
+	}
+}
+
+func TestRefreshCacheRetry(t *testing.T) {
+	db, f := newFakeDB(t, true)
+	f.setVersion(1, 1, `"v2"`)
+	f.set("errors/1.json", errorJSON("edited"), `"e1-2"`)
+	f.fail("errors/1.json", errors.New("connection reset"))
+	if err := db.RefreshCache(); err == nil {
+		t.Fatalf("Expect the revalidation error")
+	}
+
+	f.fail("errors/1.json", nil)
+	f.reset()
+	if err := db.RefreshCache(); err != nil {
+		t.Fatalf("RefreshCache: %v", err)
+	}
+	if reqs := f.requestsTo("version.json"); len(reqs) != 1 || reqs[0].ETag != `"v1"` {
+		t.Errorf("Expect the new version is not saved after the failure, got %v", reqs)
+	}
+	desc, err := db.GetErrorDesc(1)
+	if err != nil {
+		t.Fatalf("GetErrorDesc: %v", err)
+	}
+	if desc.Message != "edited" {
+		t.Errorf("Expect the entry is revalidated again, got %q", desc.Message)
+	}
+}
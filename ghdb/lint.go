@@ -0,0 +1,246 @@
+package ghdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/GlobeMC/mcla"
+)
+
+type LintSeverity int
+
+const (
+	LintWarning LintSeverity = iota
+	LintError
+)
+
+func (s LintSeverity) String() string {
+	switch s {
+	case LintWarning:
+		return "warning"
+	case LintError:
+		return "error"
+	}
+	return "LintSeverity(" + strconv.Itoa((int)(s)) + ")"
+}
+
+func (s LintSeverity) MarshalText() ([]byte, error) {
+	return ([]byte)(s.String()), nil
+}
+
+type LintIssue struct {
+	File     string       `json:"file"`
+	Severity LintSeverity `json:"severity"`
+	Message  string       `json:"message"`
+}
+
+func (i LintIssue) String() string {
+	return fmt.Sprintf("%s: %s: %s", i.Severity, i.File, i.Message)
+}
+
+type LintReport struct {
+	Issues []LintIssue `json:"issues"`
+}
+
+func (r *LintReport) add(file string, severity LintSeverity, format string, args ...any) {
+	r.Issues = append(r.Issues, LintIssue{
+		File:     file,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (r *LintReport) HasErrors() bool {
+	for _, i := range r.Issues {
+		if i.Severity == LintError {
+			return true
+		}
+	}
+	return false
+}
+
+// DuplicateThreshold is the similarity that two errors will be reported as near duplicate
+const DuplicateThreshold = 0.9
+
+var (
+	errorClassRe = regexp.MustCompile(`^(?:\*|[\w$]+)(?:\.[\w$]+)*(?:\.\*)?$`)
+	overrideRe   = regexp.MustCompile(`^[^:]+:[1-9][0-9]*$`)
+)
+
+// decodeStrict decodes a DB file, and rejects unknown fields
+func decodeStrict(buf []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(buf))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// readEntries reads all <id>.json in the directory
+func readEntries[T any](fsys fs.FS, dir string, report *LintReport) (entries map[int]*T, maxId int) {
+	entries = make(map[int]*T)
+	files, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		report.add(dir, LintError, "Cannot read directory: %v", err)
+		return
+	}
+	for _, f := range files {
+		name := path.Join(dir, f.Name())
+		if f.IsDir() {
+			continue
+		}
+		idStr, ok := strings.CutSuffix(f.Name(), ".json")
+		id, err := strconv.Atoi(idStr)
+		if !ok || err != nil || id <= 0 {
+			report.add(name, LintWarning, "File name is not in <id>.json format, it will be ignored")
+			continue
+		}
+		buf, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			report.add(name, LintError, "Cannot read file: %v", err)
+			continue
+		}
+		entry := new(T)
+		if err = decodeStrict(buf, entry); err != nil {
+			report.add(name, LintError, "Invalid JSON: %v", err)
+			continue
+		}
+		entries[id] = entry
+		maxId = max(maxId, id)
+	}
+	return
+}
+
+// readVersion reads version.json, the errors are added to the report
+func readVersion(fsys fs.FS, report *LintReport) (version versionData, ok bool) {
+	buf, err := fs.ReadFile(fsys, "version.json")
+	if err != nil {
+		report.add("version.json", LintError, "Cannot read file: %v", err)
+		return
+	}
+	if err = decodeStrict(buf, &version); err != nil {
+		report.add("version.json", LintError, "Invalid JSON: %v", err)
+		return
+	}
+	if version.Major != syntaxVersion {
+		report.add("version.json", LintError, "Syntax version %d is not supported", version.Major)
+		return
+	}
+	return version, true
+}
+
+// Lint validates a database directory before it's published.
+// If version.json is invalid, the entries are still checked, but not compared with the IDs in it
+func Lint(fsys fs.FS) (report *LintReport) {
+	report = new(LintReport)
+
+	version, versionOk := readVersion(fsys, report)
+	errs, maxErrorId := readEntries[mcla.ErrorDesc](fsys, "errors", report)
+	sols, maxSolutionId := readEntries[mcla.SolutionDesc](fsys, "solutions", report)
+
+	if versionOk {
+		if maxErrorId > version.ErrorIncId {
+			report.add("version.json", LintError, "errorIncId is %d, but errors/%d.json exists", version.ErrorIncId, maxErrorId)
+		}
+		if maxSolutionId > version.SolutionIncId {
+			report.add("version.json", LintError, "solutionIncId is %d, but solutions/%d.json exists", version.SolutionIncId, maxSolutionId)
+		}
+		for id := 1; id <= version.ErrorIncId; id++ {
+			if _, ok := errs[id]; !ok {
+				report.add(fmt.Sprintf("errors/%d.json", id), LintWarning, "Entry is missing")
+			}
+		}
+	}
+
+	referenced := make(map[int]bool, len(sols))
+	referenced[mcla.ModConflictSolutionID] = true // used by the hard-coded checks
+	errIds := sortedKeys(errs)
+	for _, id := range errIds {
+		e := errs[id]
+		name := fmt.Sprintf("errors/%d.json", id)
+		if e.Error == "" && e.Message == "" && e.Pattern == "" {
+			if len(e.Overrides) == 0 {
+				report.add(name, LintError, "Error, message and pattern are all empty")
+			}
+		} else if e.Error != "" && !errorClassRe.MatchString(e.Error) {
+			report.add(name, LintError, "Error %q is not a valid class name pattern", e.Error)
+		}
+		if e.Pattern != "" {
+			if _, err := e.CompilePattern(); err != nil {
+				report.add(name, LintError, "Invalid pattern: %v", err)
+			}
+		}
+		if i := strings.IndexByte(e.Message, '*'); i >= 0 {
+			if i != len(e.Message)-1 {
+				report.add(name, LintWarning, "Wildcard is only supported as the ` *` suffix of the message")
+			} else if !strings.HasSuffix(e.Message, " *") {
+				report.add(name, LintWarning, "Wildcard suffix must be separated by a space")
+			}
+		}
+		if len(e.Solutions) == 0 && len(e.Overrides) == 0 {
+			report.add(name, LintWarning, "No solution is given")
+		}
+		for _, sid := range e.Solutions {
+			if _, ok := sols[sid]; !ok {
+				report.add(name, LintError, "Solution %d does not exist", sid)
+			}
+			referenced[sid] = true
+		}
+		for _, sample := range e.Samples {
+			if _, err := fs.Stat(fsys, path.Clean(sample)); err != nil {
+				report.add(name, LintError, "Sample %q is not readable: %v", sample, err)
+			}
+		}
+		for _, ref := range e.Overrides {
+			if !overrideRe.MatchString(ref) {
+				report.add(name, LintError, "Override %q is not in <source>:<id> format", ref)
+			}
+		}
+	}
+	for i, id := range errIds {
+		e := errs[id]
+		if e.Message == "" {
+			continue
+		}
+		for _, id2 := range errIds[i+1:] {
+			if s := e.Similarity(errs[id2]); s >= DuplicateThreshold {
+				report.add(fmt.Sprintf("errors/%d.json", id2), LintWarning,
+					"Entry is %.0f%% similar to errors/%d.json", s*100, id)
+			}
+		}
+	}
+
+	for _, id := range sortedKeys(sols) {
+		s := sols[id]
+		name := fmt.Sprintf("solutions/%d.json", id)
+		if strings.TrimSpace(s.Description) == "" && len(s.Descriptions) == 0 {
+			report.add(name, LintError, "Description is empty")
+		}
+		for locale, desc := range s.Descriptions {
+			if mcla.NormalizeLocale(locale) != locale {
+				report.add(name, LintWarning, "Locale %q should be written as %q", locale, mcla.NormalizeLocale(locale))
+			}
+			if strings.TrimSpace(desc) == "" {
+				report.add(name, LintWarning, "Description of locale %q is empty", locale)
+			}
+		}
+		if !referenced[id] {
+			report.add(name, LintWarning, "Solution is not referenced by any error")
+		}
+	}
+	return
+}
+
+func sortedKeys[T any](m map[int]T) (keys []int) {
+	keys = make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return
+}
@@ -0,0 +1,55 @@
+package ghdb_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	. "github.com/GlobeMC/mcla/ghdb"
+)
+
+func lintFS() fstest.MapFS {
+	return fstest.MapFS{
+		"version.json":     {Data: []byte(`{"major":0,"minor":1,"patch":0,"errorIncId":1,"solutionIncId":1}`)},
+		"errors/1.json":    {Data: []byte(`{"error":"java.lang.RuntimeException","message":"Attempted to load class *","solutions":[1]}`)},
+		"solutions/1.json": {Data: []byte(`{"tags":[],"description":"Remove the client-only mod","link_to":""}`)},
+	}
+}
+
+func TestLint(t *testing.T) {
+	if report := Lint(lintFS()); len(report.Issues) != 0 {
+		t.Errorf("Expect no issue, got %v", report.Issues)
+	}
+}
+
+func TestLintBrokenVersion(t *testing.T) {
+	fsys := lintFS()
+	fsys["version.json"] = &fstest.MapFile{Data: []byte(`{"major":0,`)}
+	fsys["errors/2.json"] = &fstest.MapFile{Data: []byte(`{"error":"java.lang.RuntimeException","pattern":"(","solutions":[1]}`)}
+	report := Lint(fsys)
+	files := make(map[string]int)
+	for _, issue := range report.Issues {
+		files[issue.File]++
+	}
+	// the entries are not compared with errorIncId, but still checked
+	if len(report.Issues) != 2 || files["version.json"] != 1 || files["errors/2.json"] != 1 || !report.HasErrors() {
+		t.Errorf("Expect the version error and the entry error, got %v", report.Issues)
+	}
+}
+
+func TestLintMissingEntry(t *testing.T) {
+	fsys := lintFS()
+	fsys["version.json"] = &fstest.MapFile{Data: []byte(`{"major":0,"minor":1,"patch":0,"errorIncId":2,"solutionIncId":1}`)}
+	report := Lint(fsys)
+	if len(report.Issues) != 1 || report.Issues[0].File != "errors/2.json" || report.Issues[0].Severity != LintWarning {
+		t.Errorf("Expect a warning of the missing entry, got %v", report.Issues)
+	}
+}
+
+func TestLintDanglingSolution(t *testing.T) {
+	fsys := lintFS()
+	fsys["errors/1.json"] = &fstest.MapFile{Data: []byte(`{"error":"java.lang.RuntimeException","message":"Attempted to load class *","solutions":[1,2]}`)}
+	report := Lint(fsys)
+	if len(report.Issues) != 1 || report.Issues[0].File != "errors/1.json" || report.Issues[0].Severity != LintError {
+		t.Errorf("Expect an error of the dangling solution, got %v", report.Issues)
+	}
+}
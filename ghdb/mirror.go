@@ -0,0 +1,235 @@
+package ghdb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+)
+
+var ErrNoMirror = errors.New("No database mirror is available")
+
+const (
+	defaultMirrorRetries  = 2
+	defaultMirrorBackoff  = 500 * time.Millisecond
+	defaultMirrorTimeout  = 20 * time.Second
+	defaultMirrorCooldown = 10 * time.Minute
+)
+
+// MirrorDoFunc fetches the file at the full URL.
+// It should return an error wraps ErrNotFound (e.g. *HTTPStatusErr) if the file does not exist
+type MirrorDoFunc func(ctx context.Context, url string, req *FetchRequest) (*FetchResponse, error)
+
+// MirrorFetcher fetches the database from a list of URL prefixes.
+// If a mirror fails after retries, the next one will be used,
+// and the failed mirror will be put behind the healthy ones for a while.
+type MirrorFetcher struct {
+	Do MirrorDoFunc
+	// Retries is how many times to retry on one mirror before fallback to the next
+	Retries int
+	// Backoff is the delay before the first retry, and it will be doubled for every retry
+	Backoff time.Duration
+	// Timeout limits each attempt, including reading the body
+	Timeout time.Duration
+	// Cooldown is the max duration that a failing mirror will be deprioritized
+	Cooldown time.Duration
+
+	mux     sync.RWMutex
+	mirrors []*mirrorState
+}
+
+var _ Fetcher = (*MirrorFetcher)(nil)
+
+type mirrorState struct {
+	prefix        string
+	failures      int // consecutive failures
+	lastError     error
+	disabledUntil time.Time
+}
+
+type MirrorStatus struct {
+	Prefix        string    `json:"prefix"`
+	Failures      int       `json:"failures"`
+	LastError     string    `json:"lastError,omitempty"`
+	DisabledUntil time.Time `json:"disabledUntil"`
+}
+
+func NewMirrorFetcher(do MirrorDoFunc, prefixes ...string) (f *MirrorFetcher) {
+	f = &MirrorFetcher{
+		Do:       do,
+		Retries:  defaultMirrorRetries,
+		Backoff:  defaultMirrorBackoff,
+		Timeout:  defaultMirrorTimeout,
+		Cooldown: defaultMirrorCooldown,
+	}
+	f.SetMirrors(prefixes)
+	return
+}
+
+// SetMirrors replaces the mirror list and resets their health
+func (f *MirrorFetcher) SetMirrors(prefixes []string) {
+	mirrors := make([]*mirrorState, len(prefixes))
+	for i, p := range prefixes {
+		mirrors[i] = &mirrorState{prefix: p}
+	}
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.mirrors = mirrors
+}
+
+func (f *MirrorFetcher) Mirrors() (status []MirrorStatus) {
+	f.mux.RLock()
+	defer f.mux.RUnlock()
+	status = make([]MirrorStatus, len(f.mirrors))
+	for i, m := range f.mirrors {
+		status[i] = MirrorStatus{
+			Prefix:        m.prefix,
+			Failures:      m.failures,
+			DisabledUntil: m.disabledUntil,
+		}
+		if m.lastError != nil {
+			status[i].LastError = m.lastError.Error()
+		}
+	}
+	return
+}
+
+// orderedMirrors returns the healthy mirrors in the configured order,
+// followed by the deprioritized ones which will be available earlier
+func (f *MirrorFetcher) orderedMirrors() []*mirrorState {
+	now := time.Now()
+	f.mux.RLock()
+	defer f.mux.RUnlock()
+	healthy := make([]*mirrorState, 0, len(f.mirrors))
+	var failing []*mirrorState
+	for _, m := range f.mirrors {
+		if m.disabledUntil.After(now) {
+			failing = append(failing, m)
+		} else {
+			healthy = append(healthy, m)
+		}
+	}
+	slices.SortStableFunc(failing, func(a, b *mirrorState) int {
+		return a.disabledUntil.Compare(b.disabledUntil)
+	})
+	return append(healthy, failing...)
+}
+
+func (f *MirrorFetcher) markSuccess(m *mirrorState) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	m.failures = 0
+	m.lastError = nil
+	m.disabledUntil = time.Time{}
+}
+
+func (f *MirrorFetcher) markFailure(m *mirrorState, err error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	m.failures++
+	m.lastError = err
+	cooldown := min(f.Cooldown, f.Backoff<<min(m.failures+4, 30))
+	m.disabledUntil = time.Now().Add(cooldown)
+}
+
+// isRetryable reports whether an error might be temporary
+func isRetryable(err error) bool {
+	var se *HTTPStatusErr
+	if errors.As(err, &se) {
+		return se.StatusCode >= 500 || se.StatusCode == 429
+	}
+	return !errors.Is(err, ErrNotFound)
+}
+
+// sleepContext waits for the duration, or returns the cause of the context once it's done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// Fetch tries the mirrors in order, the error wraps ErrNotFound if any mirror answered that the file does not exist
+func (f *MirrorFetcher) Fetch(req *FetchRequest) (res *FetchResponse, err error) {
+	mirrors := f.orderedMirrors()
+	if len(mirrors) == 0 {
+		return nil, ErrNoMirror
+	}
+	ctx := req.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var (
+		errs     []error
+		notFound error
+	)
+	for _, m := range mirrors {
+		for i := 0; i <= f.Retries; i++ {
+			if i > 0 {
+				if err = sleepContext(ctx, f.Backoff<<(i-1)); err != nil {
+					return
+				}
+			}
+			if res, err = f.fetchFrom(ctx, m.prefix, req); err == nil {
+				f.markSuccess(m)
+				return
+			}
+			if ctx.Err() != nil {
+				// the request is cancelled, it's not the mirror's fault
+				return nil, context.Cause(ctx)
+			}
+			if !isRetryable(err) {
+				break
+			}
+		}
+		if errors.Is(err, ErrNotFound) {
+			// the mirror may not be synchronized yet, try the others but don't blame it
+			notFound = err
+			continue
+		}
+		f.markFailure(m, err)
+		errs = append(errs, err)
+	}
+	if notFound != nil {
+		if len(errs) == 0 {
+			return nil, notFound
+		}
+		// the other mirrors may just be unreachable, so the file is still reported as not found
+		return nil, errors.Join(append([]error{notFound}, errs...)...)
+	}
+	return nil, errors.Join(errs...)
+}
+
+// fetchFrom reads the whole body within the timeout, DB entries are small
+func (f *MirrorFetcher) fetchFrom(ctx context.Context, prefix string, req *FetchRequest) (res *FetchResponse, err error) {
+	u, err := url.JoinPath(prefix, req.Path)
+	if err != nil {
+		return
+	}
+	if f.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.Timeout)
+		defer cancel()
+	}
+	if res, err = f.Do(ctx, u, req); err != nil {
+		return
+	}
+	if res.NotModified {
+		return
+	}
+	buf, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(buf))
+	return
+}
@@ -0,0 +1,173 @@
+package ghdb_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/GlobeMC/mcla/ghdb"
+)
+
+func httpDo(ctx context.Context, url string, req *FetchRequest) (*FetchResponse, error) {
+	hreq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := http.DefaultClient.Do(hreq)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, &HTTPStatusErr{URL: url, StatusCode: res.StatusCode}
+	}
+	return &FetchResponse{Body: res.Body}, nil
+}
+
+// mirrorServer answers every request with the status code, and counts the requests
+type mirrorServer struct {
+	*httptest.Server
+	status   atomic.Int32
+	requests atomic.Int32
+}
+
+func newMirrorServer(t *testing.T, status int) *mirrorServer {
+	s := new(mirrorServer)
+	s.status.Store(int32(status))
+	s.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		s.requests.Add(1)
+		status := int(s.status.Load())
+		rw.WriteHeader(status)
+		if status == http.StatusOK {
+			io.WriteString(rw, s.URL)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newTestMirrorFetcher(mirrors ...*mirrorServer) *MirrorFetcher {
+	prefixes := make([]string, len(mirrors))
+	for i, m := range mirrors {
+		prefixes[i] = m.URL
+	}
+	f := NewMirrorFetcher(httpDo, prefixes...)
+	f.Retries = 1
+	f.Backoff = time.Millisecond
+	return f
+}
+
+// fetchFrom returns the URL of the mirror which served the request
+func fetchFrom(t *testing.T, f *MirrorFetcher) string {
+	t.Helper()
+	res, err := f.Fetch(&FetchRequest{Path: "version.json"})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	defer res.Body.Close()
+	buf, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	return (string)(buf)
+}
+
+func TestMirrorFallback(t *testing.T) {
+	bad, good := newMirrorServer(t, http.StatusBadGateway), newMirrorServer(t, http.StatusOK)
+	f := newTestMirrorFetcher(bad, good)
+	if from := fetchFrom(t, f); from != good.URL {
+		t.Fatalf("Expect the response from the second mirror, got %q", from)
+	}
+	if n := bad.requests.Load(); n != 2 {
+		t.Errorf("Expect the failing mirror is retried once, got %d requests", n)
+	}
+	status := f.Mirrors()
+	if status[0].Failures != 1 || status[0].LastError == "" || !status[0].DisabledUntil.After(time.Now()) {
+		t.Errorf("Expect the failing mirror is deprioritized, got %+v", status[0])
+	}
+	if status[1].Failures != 0 {
+		t.Errorf("Expect the healthy mirror has no failure, got %+v", status[1])
+	}
+
+	// the healthy mirror is tried first
+	bad.requests.Store(0)
+	if from := fetchFrom(t, f); from != good.URL {
+		t.Fatalf("Expect the response from the healthy mirror, got %q", from)
+	}
+	if n := bad.requests.Load(); n != 0 {
+		t.Errorf("Expect the deprioritized mirror is not requested, got %d requests", n)
+	}
+}
+
+func TestMirrorCooldown(t *testing.T) {
+	first, second := newMirrorServer(t, http.StatusServiceUnavailable), newMirrorServer(t, http.StatusOK)
+	f := newTestMirrorFetcher(first, second)
+	f.Cooldown = 20 * time.Millisecond
+	fetchFrom(t, f)
+	first.status.Store(http.StatusOK)
+	if from := fetchFrom(t, f); from != second.URL {
+		t.Errorf("Expect the first mirror is deprioritized during the cooldown, got %q", from)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if from := fetchFrom(t, f); from != first.URL {
+		t.Errorf("Expect the first mirror is preferred after the cooldown, got %q", from)
+	}
+	if status := f.Mirrors(); status[0].Failures != 0 {
+		t.Errorf("Expect the failures are reset after a success, got %+v", status[0])
+	}
+}
+
+func TestMirrorNotFound(t *testing.T) {
+	missing, broken := newMirrorServer(t, http.StatusNotFound), newMirrorServer(t, http.StatusInternalServerError)
+	f := newTestMirrorFetcher(missing, broken)
+	_, err := f.Fetch(&FetchRequest{Path: "errors/1.json"})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expect ErrNotFound when a mirror answered 404, got %v", err)
+	}
+	if n := missing.requests.Load(); n != 1 {
+		t.Errorf("Expect 404 is not retried, got %d requests", n)
+	}
+	if status := f.Mirrors(); status[0].Failures != 0 || status[1].Failures != 1 {
+		t.Errorf("Expect only the broken mirror is blamed, got %+v", status)
+	}
+}
+
+func TestMirrorNotRetryable(t *testing.T) {
+	var calls atomic.Int32
+	f := NewMirrorFetcher(func(ctx context.Context, url string, req *FetchRequest) (*FetchResponse, error) {
+		calls.Add(1)
+		return nil, fmt.Errorf("wrapped: %w", &HTTPStatusErr{URL: url, StatusCode: http.StatusForbidden})
+	}, "https://example.com")
+	f.Backoff = time.Millisecond
+	if _, err := f.Fetch(&FetchRequest{Path: "version.json"}); err == nil {
+		t.Fatalf("Expect an error")
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expect the wrapped 403 is not retried, got %d calls", n)
+	}
+}
+
+func TestMirrorCancel(t *testing.T) {
+	bad := newMirrorServer(t, http.StatusBadGateway)
+	f := newTestMirrorFetcher(bad)
+	f.Backoff = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := f.Fetch(&FetchRequest{Context: ctx, Path: "version.json"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expect the context error, got %v", err)
+	}
+	if d := time.Since(start); d > 10*time.Second {
+		t.Errorf("Expect the backoff is cancelled, waited %v", d)
+	}
+	if status := f.Mirrors(); status[0].Failures != 0 {
+		t.Errorf("Expect the mirror is not blamed for the cancellation, got %+v", status[0])
+	}
+}
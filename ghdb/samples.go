@@ -0,0 +1,119 @@
+package ghdb
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/GlobeMC/mcla"
+)
+
+// DefaultSampleThreshold is the minimum match that a sample is considered passed
+const DefaultSampleThreshold = 0.8
+
+type SampleResult struct {
+	ErrorID int    `json:"errorId"`
+	Sample  string `json:"sample"`
+	Passed  bool   `json:"passed"`
+	// Match is the best match of the expected entry among the errors in the sample
+	Match float32 `json:"match"`
+	// TopID and TopMatch describes the top match when the sample is failed
+	TopID    int     `json:"topId,omitempty"`
+	TopMatch float32 `json:"topMatch,omitempty"`
+	Error    string  `json:"error,omitempty"`
+}
+
+func (r *SampleResult) String() string {
+	if r.Error != "" {
+		return fmt.Sprintf("errors/%d.json: %s: %s", r.ErrorID, r.Sample, r.Error)
+	}
+	if r.Passed {
+		return fmt.Sprintf("errors/%d.json: %s: matched %.2f%%", r.ErrorID, r.Sample, r.Match*100)
+	}
+	return fmt.Sprintf("errors/%d.json: %s: matched %.2f%%, but the top match is errors/%d.json with %.2f%%",
+		r.ErrorID, r.Sample, r.Match*100, r.TopID, r.TopMatch*100)
+}
+
+// CheckSamples runs every sample of the database through Analyzer.DoLogStream,
+// and checks if the owner entry is the top match above the threshold
+func CheckSamples(ctx context.Context, fsys fs.FS, threshold float32) (results []*SampleResult, err error) {
+	db := &ErrDB{
+		Fetcher: FSFetcher{FS: fsys},
+		Cache:   NewInMemoryCache(),
+	}
+	if err = db.RefreshCache(); err != nil {
+		return
+	}
+	var descs []*mcla.ErrorDesc
+	if err = db.ForEachErrors(func(e *mcla.ErrorDesc) error {
+		if len(e.Samples) > 0 {
+			descs = append(descs, e)
+		}
+		return nil
+	}); err != nil {
+		return
+	}
+	analyzer := mcla.NewAnalyzer(db)
+	for _, e := range descs {
+		for _, sample := range e.Samples {
+			res := &SampleResult{
+				ErrorID: e.ID,
+				Sample:  sample,
+			}
+			if err := checkSample(ctx, analyzer, fsys, res, threshold); err != nil {
+				if ctx.Err() != nil {
+					return nil, context.Cause(ctx)
+				}
+				res.Error = err.Error()
+			}
+			if res.Passed {
+				res.TopID, res.TopMatch = 0, 0
+			}
+			results = append(results, res)
+		}
+	}
+	return
+}
+
+// checkSample analyzes the sample as a log stream, and records how well the entry matches it
+func checkSample(ctx context.Context, analyzer *mcla.Analyzer, fsys fs.FS, res *SampleResult, threshold float32) (err error) {
+	fd, err := fsys.Open(path.Clean(res.Sample))
+	if err != nil {
+		return
+	}
+	defer fd.Close()
+	resCh, sctx := analyzer.DoLogStream(ctx, fd)
+	for {
+		select {
+		case r := <-resCh:
+			if r == nil {
+				return
+			}
+			var (
+				top *mcla.SolutionPossibility
+				own float32
+			)
+			for i, m := range r.Matched {
+				if top == nil || m.Match > top.Match {
+					top = &r.Matched[i]
+				}
+				if m.ErrorDesc.ID == res.ErrorID {
+					own = max(own, m.Match)
+				}
+			}
+			if top == nil {
+				continue
+			}
+			if own >= top.Match && own >= threshold {
+				res.Passed = true
+			}
+			if own >= res.Match {
+				res.Match = own
+				res.TopID, res.TopMatch = top.ErrorDesc.ID, top.Match
+			}
+		case <-sctx.Done():
+			return context.Cause(sctx)
+		}
+	}
+}
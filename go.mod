@@ -3,3 +3,5 @@ module github.com/GlobeMC/mcla
 go 1.23.0
 
 require github.com/kmcsr/go-ringbuf v1.3.0
+
+require golang.org/x/text v0.21.0
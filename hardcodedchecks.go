@@ -11,9 +11,15 @@ const (
 	spongepoweredInjectionErrorClass = "org.spongepowered.asm.mixin.injection.throwables.InjectionError"
 )
 
+// HardCodedChecks runs the checks which cannot be described by the database entries,
+// the mixin logs of the latest log stream of DoLogStream are used
 func (a *Analyzer) HardCodedChecks(jerr *JavaError) (desc *ErrorDesc, err error) {
+	return a.hardCodedChecks(jerr, a.latestRecorder.Load())
+}
+
+func (a *Analyzer) hardCodedChecks(jerr *JavaError, recorder *logRecorder) (desc *ErrorDesc, err error) {
 	if jerr.Class == spongepoweredInjectionErrorClass {
-		if desc, err = a.hardCodedRedirectConflictCheck(jerr); desc != nil || err != nil {
+		if desc, err = a.hardCodedRedirectConflictCheck(jerr, recorder); desc != nil || err != nil {
 			return
 		}
 	}
@@ -33,7 +39,7 @@ var (
 // ...
 // Caused by: org.spongepowered.asm.mixin.injection.throwables.InjectionError: Critical injection failure: Redirector shouldFreezeWithClimate(Lnet/minecraft/world/level/biome/Biome;Lnet/minecraft/core/BlockPos;Lnet/minecraft/world/level/LevelReader;)Z in tfc.mixins.json:BiomeMixin failed injection check, (0/1) succeeded. Scanned 1 target(s). Using refmap tfc.refmap.json
 // ```
-func (a *Analyzer) hardCodedRedirectConflictCheck(jerr *JavaError) (desc *ErrorDesc, err error) {
+func (a *Analyzer) hardCodedRedirectConflictCheck(jerr *JavaError, recorder *logRecorder) (desc *ErrorDesc, err error) {
 	const redirectorMessage = "Critical injection failure: Redirector "
 	targetName, ok := strings.CutPrefix(jerr.Message, redirectorMessage)
 	if !ok {
@@ -44,7 +50,7 @@ func (a *Analyzer) hardCodedRedirectConflictCheck(jerr *JavaError) (desc *ErrorD
 		return
 	}
 	var mod1, mod2, method string
-	for line := range a.recentMixinLogs.IterReversed() {
+	for _, line := range recorder.mixinLogs() {
 		matches := mixinRedirectConflictRe.FindStringSubmatch(line)
 		if matches != nil {
 			mod1, method, mod2 = matches[1], matches[2], matches[3]
@@ -0,0 +1,43 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"context"
+	"strings"
+)
+
+const redirectConflictLog = `[12:00:00] [main/WARN] [mixin/]: @Redirect conflict. Skipping sereneseasons.mixins.json:BiomeMixin->@Redirect::shouldFreezeWithClimate(Lnet/minecraft/world/level/biome/Biome;)Z with priority 1000, already redirected by tfc.mixins.json:BiomeMixin->@Redirect::shouldFreezeWithClimate(Lnet/minecraft/world/level/biome/Biome;)Z with priority 1000
+org.spongepowered.asm.mixin.injection.throwables.InjectionError: Critical injection failure: Redirector shouldFreezeWithClimate(Lnet/minecraft/world/level/biome/Biome;)Z in tfc.mixins.json:BiomeMixin failed injection check, (0/1) succeeded. Scanned 1 target(s). Using refmap tfc.refmap.json
+	at org.spongepowered.asm.mixin.injection.struct.InjectionInfo.postInject(InjectionInfo.java:1)
+`
+
+func TestRedirectConflictCheck(t *testing.T) {
+	a := NewAnalyzer(&mapErrorDB{})
+	result, _ := a.DoLogStream(context.Background(), strings.NewReader(redirectConflictLog))
+	var jerr *JavaError
+	for res := range result {
+		if len(res.Matched) != 1 || res.Matched[0].ErrorDesc.Solutions[0] != ModConflictSolutionID {
+			t.Fatalf("Expect the mod conflict is found in the stream, got %v", res.Matched)
+		}
+		if data := res.Matched[0].ErrorDesc.Data; data["mod1"] != "sereneseasons" || data["mod2"] != "tfc" {
+			t.Errorf("Unexpected conflict data %v", data)
+		}
+		jerr = res.Error
+	}
+	if jerr == nil {
+		t.Fatalf("Expect an error in the log")
+	}
+	// the direct calls use the mixin logs of the latest stream
+	desc, err := a.HardCodedChecks(jerr)
+	if err != nil {
+		t.Fatalf("HardCodedChecks: %v", err)
+	}
+	if desc == nil || desc.Data["mod1"] != "sereneseasons" {
+		t.Errorf("Expect the mod conflict is found by HardCodedChecks, got %v", desc)
+	}
+	if matched, err := a.DoError(jerr); err != nil || len(matched) != 1 {
+		t.Errorf("Expect the mod conflict is found by DoError, got %v, %v", matched, err)
+	}
+}
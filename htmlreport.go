@@ -0,0 +1,116 @@
+package mcla
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+//go:embed htmlreport.html
+var htmlReportSource string
+
+var htmlReportTmpl = template.Must(template.New("report").Funcs(template.FuncMap{
+	"percent": func(match float32) int {
+		return (int)(match*100 + 0.5)
+	},
+	"solution": func(sol *SolutionDesc) template.HTML {
+		return (template.HTML)(RenderSolution(sol, nil, RenderHTML))
+	},
+	"chains":  GroupChainResults,
+	"causes":  errorCauses,
+	"suspect": isSuspectFrame,
+	"title": func(jerr *JavaError) string {
+		title, _ := errorTitle(jerr)
+		return title
+	},
+	"details": crashReportDetails,
+}).Parse(htmlReportSource))
+
+// HTMLReport is a self-contained HTML page of the analysis results,
+// the styles and scripts are inlined so it can be sent as a single file and opened offline
+type HTMLReport struct {
+	Title     string
+	Generated time.Time
+	Files     []*InstanceFileResult
+}
+
+// NewHTMLReport creates a report from an InstanceReport, the files can also be appended later
+func NewHTMLReport(title string, report *InstanceReport) *HTMLReport {
+	r := &HTMLReport{
+		Title:     title,
+		Generated: time.Now(),
+	}
+	if report != nil {
+		r.Files = report.Files
+	}
+	return r
+}
+
+// AddLog appends the results of DoLogStream of a log file
+func (r *HTMLReport) AddLog(name string, results []*ErrorResult) {
+	kind, _ := ClassifyLogFile(name)
+	r.Files = append(r.Files, &InstanceFileResult{
+		Name:   name,
+		Kind:   kind,
+		Errors: results,
+	})
+}
+
+// AddCrashReport appends a parsed crash report, and the results of its errors which can be nil
+func (r *HTMLReport) AddCrashReport(name string, report *CrashReport, results []*ErrorResult) {
+	r.Files = append(r.Files, &InstanceFileResult{
+		Name:        name,
+		Kind:        LogKindCrashReport,
+		CrashReport: report,
+		Errors:      results,
+	})
+}
+
+func (r *HTMLReport) Render(w io.Writer) error {
+	return htmlReportTmpl.Execute(w, r)
+}
+
+func errorCauses(jerr *JavaError) (causes []*JavaError) {
+	for jerr = jerr.CausedBy; jerr != nil; jerr = jerr.CausedBy {
+		causes = append(causes, jerr)
+	}
+	return
+}
+
+func isSuspectFrame(s StackInfo) bool {
+	return !s.IsFramework()
+}
+
+type detailsEntry struct {
+	Section string
+	Key     string
+	Value   string
+}
+
+// crashReportDetails flattens the details sections of the report in a stable order
+func crashReportDetails(report *CrashReport) (entries []detailsEntry) {
+	sections := make([]string, 0, len(report.OtherDetails))
+	for name := range report.OtherDetails {
+		sections = append(sections, name)
+	}
+	slices.Sort(sections)
+	for _, name := range sections {
+		details := report.OtherDetails[name].Details
+		keys := make([]string, 0, len(details))
+		for key := range details {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			entries = append(entries, detailsEntry{
+				Section: name,
+				Key:     key,
+				Value:   strings.Join(details[key], "\n"),
+			})
+		}
+	}
+	return
+}
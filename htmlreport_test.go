@@ -0,0 +1,74 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"strings"
+)
+
+func TestStackInfoIsFramework(t *testing.T) {
+	cases := []struct {
+		info   StackInfo
+		expect bool
+	}{
+		{StackInfo{Class: "java.lang.Thread", Method: "run"}, true},
+		{StackInfo{Class: "net.minecraft.client.Minecraft", Method: "run"}, true},
+		{StackInfo{Class: "net.minecraft.client.Minecraft", Method: "handler$zza000$examplemod$onTick"}, false},
+		{StackInfo{Class: "com.example.mod.ExampleMod", Method: "<init>"}, false},
+	}
+	for _, c := range cases {
+		if got := c.info.IsFramework(); got != c.expect {
+			t.Errorf("%s.%s IsFramework() = %v, expect %v", c.info.Class, c.info.Method, got, c.expect)
+		}
+	}
+	s := StackInfo{Raw: "at com.example.Foo.bar(Foo.java:1) ~[example-1.0.jar%2363!/:?]", Method: "handler$abc001$examplemod$bar"}
+	if jar := s.JarName(); jar != "example-1.0.jar" {
+		t.Errorf("Unexpected jar name %q", jar)
+	}
+	if owner := s.MixinOwner(); owner != "examplemod" {
+		t.Errorf("Unexpected mixin owner %q", owner)
+	}
+}
+
+func TestHTMLReport(t *testing.T) {
+	jerr := &JavaError{
+		Class:   "java.lang.RuntimeException",
+		Message: "<boom>",
+		LineNo:  3,
+		Stacktrace: Stacktrace{
+			{Raw: "at com.example.Foo.bar(Foo.java:1)", Class: "com.example.Foo", Method: "bar"},
+			{Raw: "at java.lang.Thread.run(Thread.java:1)", Class: "java.lang.Thread", Method: "run"},
+		},
+	}
+	report := NewHTMLReport("Test report", nil)
+	report.AddLog("logs/latest.log", []*ErrorResult{{
+		Error: jerr,
+		File:  "logs/latest.log",
+		Solutions: []*ResolvedSolution{
+			{ID: 1, Match: 0.75, Solution: &SolutionDesc{Description: "Remove <Foo>", LinkTo: "https://example.com"}},
+		},
+	}})
+	var sb strings.Builder
+	if err := report.Render(&sb); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	out := sb.String()
+	for _, expect := range []string{
+		"<title>Test report</title>",
+		"java.lang.RuntimeException: &lt;boom&gt;",
+		`<span class="frame suspect">at com.example.Foo.bar(Foo.java:1)</span>`,
+		`<span class="frame">at java.lang.Thread.run(Thread.java:1)</span>`,
+		"Remove &lt;Foo&gt;",
+		`style="width: 75%"`,
+		"<style>",
+		"<script>",
+	} {
+		if !strings.Contains(out, expect) {
+			t.Errorf("Expect %q in the report", expect)
+		}
+	}
+	if strings.Contains(out, "<link ") || strings.Contains(out, "<script src") {
+		t.Errorf("The report should not load external resources")
+	}
+}
@@ -0,0 +1,320 @@
+package mcla
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+)
+
+var ErrNotCrashReport = errors.New("Not a crash report")
+
+// MaxInstanceFileSize is the max size of a discovered file after decompression, 256 MiB by default,
+// so a small archive cannot expand to gigabytes when it's analyzed
+var MaxInstanceFileSize int64 = 256 * 1024 * 1024
+
+var ErrFileTooLarge = errors.New("File is larger than MaxInstanceFileSize")
+
+// LogKind is the type of a file in a game instance directory
+type LogKind string
+
+const (
+	LogKindCrashReport LogKind = "crash-report" // crash-reports/*.txt
+	LogKindJVMCrash    LogKind = "jvm-crash"    // hs_err_pid*.log
+	LogKindLatest      LogKind = "latest"       // logs/latest.log
+	LogKindDebug       LogKind = "debug"        // logs/debug.log
+	LogKindArchived    LogKind = "archived"     // logs/*.log.gz
+	LogKindOther       LogKind = "log"          // other *.log files
+)
+
+// the order of the kinds in an InstanceReport, the most useful files come first
+var logKindOrder = []LogKind{LogKindCrashReport, LogKindJVMCrash, LogKindLatest, LogKindDebug, LogKindArchived, LogKindOther}
+
+// ClassifyLogFile reports the kind of the file by its slash separated path.
+// The instance may be nested in other directories, e.g. an archive of the whole `.minecraft`
+func ClassifyLogFile(name string) (kind LogKind, ok bool) {
+	dir, base := path.Split(name)
+	parent := path.Base(dir)
+	switch {
+	case strings.HasPrefix(base, "hs_err_pid") && strings.HasSuffix(base, ".log"):
+		return LogKindJVMCrash, true
+	case parent == "crash-reports" && strings.HasSuffix(base, ".txt"):
+		return LogKindCrashReport, true
+	case parent != "logs":
+		return "", false
+	case base == "latest.log":
+		return LogKindLatest, true
+	case base == "debug.log":
+		return LogKindDebug, true
+	case strings.HasSuffix(base, ".log.gz"):
+		return LogKindArchived, true
+	}
+	return "", false
+}
+
+// InstanceFile is a discovered file, it is decompressed when opening if it's gzipped
+type InstanceFile struct {
+	Name string
+	Kind LogKind
+	open func() (io.ReadCloser, error)
+}
+
+// Open opens the file, reading more than MaxInstanceFileSize bytes from it fails with ErrFileTooLarge
+func (f *InstanceFile) Open() (r io.ReadCloser, err error) {
+	if r, err = f.open(); err != nil {
+		return
+	}
+	if strings.HasSuffix(f.Name, ".gz") {
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+		r = &gzipReadCloser{gr, r}
+	}
+	return &limitedReadCloser{r, MaxInstanceFileSize}, nil
+}
+
+// limitedReadCloser fails with ErrFileTooLarge once more than n bytes are read
+type limitedReadCloser struct {
+	io.ReadCloser
+	n int64 // the bytes can still be read
+}
+
+func (r *limitedReadCloser) Read(buf []byte) (n int, err error) {
+	if r.n < 0 {
+		return 0, ErrFileTooLarge
+	}
+	if int64(len(buf)) > r.n+1 {
+		buf = buf[:r.n+1]
+	}
+	n, err = r.ReadCloser.Read(buf)
+	if r.n -= int64(n); r.n < 0 {
+		return n + int(r.n), ErrFileTooLarge
+	}
+	return
+}
+
+type gzipReadCloser struct {
+	*gzip.Reader
+	file io.Closer
+}
+
+func (r *gzipReadCloser) Close() error {
+	r.Reader.Close()
+	return r.file.Close()
+}
+
+func sortInstanceFiles(files []*InstanceFile) {
+	slices.SortStableFunc(files, func(a, b *InstanceFile) int {
+		if c := slices.Index(logKindOrder, a.Kind) - slices.Index(logKindOrder, b.Kind); c != 0 {
+			return c
+		}
+		// the dated names are sorted from the newest to the oldest
+		return strings.Compare(b.Name, a.Name)
+	})
+}
+
+// FindInstanceFiles discovers the logs in an instance directory or a zip archive
+func FindInstanceFiles(fsys fs.FS) (files []*InstanceFile, err error) {
+	err = fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if kind, ok := ClassifyLogFile(name); ok {
+			files = append(files, &InstanceFile{
+				Name: name,
+				Kind: kind,
+				open: func() (io.ReadCloser, error) { return fsys.Open(name) },
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortInstanceFiles(files)
+	return
+}
+
+// FindTarFiles discovers the logs in a tar archive, the discovered files are read into memory.
+// The files larger than MaxInstanceFileSize are not read, and fail with ErrFileTooLarge when they are opened
+func FindTarFiles(r io.Reader) (files []*InstanceFile, err error) {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := strings.TrimPrefix(path.Clean(hdr.Name), "/")
+		kind, ok := ClassifyLogFile(name)
+		if !ok {
+			continue
+		}
+		file := &InstanceFile{
+			Name: name,
+			Kind: kind,
+		}
+		if hdr.Size > MaxInstanceFileSize {
+			file.open = func() (io.ReadCloser, error) { return nil, ErrFileTooLarge }
+		} else {
+			buf, err := io.ReadAll(io.LimitReader(tr, MaxInstanceFileSize))
+			if err != nil {
+				return nil, err
+			}
+			file.open = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(buf)), nil }
+		}
+		files = append(files, file)
+	}
+	sortInstanceFiles(files)
+	return
+}
+
+type InstanceFileResult struct {
+	Name        string         `json:"name"`
+	Kind        LogKind        `json:"kind"`
+	CrashReport *CrashReport   `json:"crashReport,omitempty"`
+	JVMCrash    *JVMCrashLog   `json:"jvmCrash,omitempty"`
+	Errors      []*ErrorResult `json:"errors,omitempty"`
+	// Duplicates is the number of errors omitted since they were reported by the previous files,
+	// debug.log usually contains the errors in latest.log
+	Duplicates int `json:"duplicates,omitempty"`
+	// Failure is set when the file cannot be read or parsed
+	Failure string `json:"failure,omitempty"`
+	// Warnings are the problems of the log which are tolerated
+	Warnings []ScanWarning `json:"warnings,omitempty"`
+	// EmbeddedReports are the crash reports printed in the log, their errors are also in Errors
+	EmbeddedReports []*EmbeddedReportResult `json:"embeddedReports,omitempty"`
+}
+
+// InstanceReport is the combined result of all logs in an instance
+type InstanceReport struct {
+	Files []*InstanceFileResult `json:"files"`
+}
+
+// errorKey identifies the same error logged in different files
+func errorKey(jerr *JavaError) string {
+	var sb strings.Builder
+	for ; jerr != nil; jerr = jerr.CausedBy {
+		sb.WriteString(jerr.Class)
+		sb.WriteByte(':')
+		sb.WriteString(jerr.Message)
+		for _, s := range jerr.Stacktrace {
+			sb.WriteByte('\n')
+			sb.WriteString(s.Raw)
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// AnalyzeInstance runs the parser of each file in order and combines the results.
+// The failure of a file is recorded in its result, and the other files are still analyzed
+func (a *Analyzer) AnalyzeInstance(ctx context.Context, files []*InstanceFile) (report *InstanceReport, err error) {
+	report = &InstanceReport{
+		Files: make([]*InstanceFileResult, 0, len(files)),
+	}
+	seen := make(map[string]struct{})
+	for _, file := range files {
+		res := a.AnalyzeFile(ctx, file)
+		if ctx.Err() != nil {
+			return nil, context.Cause(ctx)
+		}
+		kept := res.Errors[:0]
+		for _, e := range res.Errors {
+			key := errorKey(e.Error)
+			if _, ok := seen[key]; ok {
+				res.Duplicates++
+				continue
+			}
+			seen[key] = struct{}{}
+			kept = append(kept, e)
+		}
+		res.Errors = kept
+		report.Files = append(report.Files, res)
+	}
+	return
+}
+
+// AnalyzeFile runs the parser of the file's kind, the failure is recorded in the result
+func (a *Analyzer) AnalyzeFile(ctx context.Context, file *InstanceFile) (res *InstanceFileResult) {
+	res = &InstanceFileResult{
+		Name: file.Name,
+		Kind: file.Kind,
+	}
+	if err := a.analyzeInstanceFile(ctx, file, res); err != nil {
+		if err == io.EOF && file.Kind == LogKindCrashReport {
+			err = ErrNotCrashReport
+		}
+		res.Failure = err.Error()
+	}
+	return
+}
+
+func (a *Analyzer) analyzeInstanceFile(ctx context.Context, file *InstanceFile, res *InstanceFileResult) (err error) {
+	fd, err := file.Open()
+	if err != nil {
+		return
+	}
+	defer fd.Close()
+	r, err := NewDecodedReader(fd, a.Encoding)
+	if err != nil {
+		return
+	}
+	switch file.Kind {
+	case LogKindCrashReport:
+		if res.CrashReport, err = ParseCrashReport(r); err != nil {
+			return
+		}
+		var cr *CrashReportResult
+		if cr, err = a.AnalyzeCrashReport(res.CrashReport); err != nil {
+			return
+		}
+		for _, e := range cr.Errors {
+			e.File = file.Name
+		}
+		res.Errors = cr.Errors
+	case LogKindJVMCrash:
+		res.JVMCrash, err = ParseJVMCrashLog(r)
+	default:
+		result, sctx := a.DoLogStream(ctx, r)
+		for {
+			select {
+			case e := <-result:
+				if e == nil { // done
+					sortErrorResults(res.Errors)
+					res.Warnings = LogWarnings(sctx)
+					res.EmbeddedReports = LogCrashReports(sctx)
+					return
+				}
+				e.File = file.Name
+				res.Errors = append(res.Errors, e)
+			case <-sctx.Done():
+				return context.Cause(sctx)
+			}
+		}
+	}
+	return
+}
+
+// sortErrorResults sorts the results of DoLogStream by the lines since they are analyzed concurrently
+func sortErrorResults(results []*ErrorResult) {
+	slices.SortStableFunc(results, func(a, b *ErrorResult) int {
+		return a.Error.LineNo - b.Error.LineNo
+	})
+}
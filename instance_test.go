@@ -0,0 +1,159 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"maps"
+	"strings"
+	"testing/fstest"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func TestClassifyLogFile(t *testing.T) {
+	cases := []struct {
+		name string
+		kind LogKind
+		ok   bool
+	}{
+		{"logs/latest.log", LogKindLatest, true},
+		{".minecraft/logs/debug.log", LogKindDebug, true},
+		{"logs/2024-01-01-1.log.gz", LogKindArchived, true},
+		{"crash-reports/crash-2024-01-01_00.00.00-client.txt", LogKindCrashReport, true},
+		{"hs_err_pid1234.log", LogKindJVMCrash, true},
+		{"latest.log", "", false},
+		{"config/logs.txt", "", false},
+		{"crash-reports/readme.md", "", false},
+	}
+	for _, c := range cases {
+		kind, ok := ClassifyLogFile(c.name)
+		if kind != c.kind || ok != c.ok {
+			t.Errorf("ClassifyLogFile(%q) = %q, %v; expect %q, %v", c.name, kind, ok, c.kind, c.ok)
+		}
+	}
+}
+
+const instanceLog = `[00:00:00] [main/ERROR]: Failed
+java.lang.RuntimeException: Attempted to load class net/minecraft/client/Minecraft for invalid dist DEDICATED_SERVER
+	at net.minecraftforge.fml.loading.RuntimeDistCleaner.processClassWithFlags(RuntimeDistCleaner.java:57) ~[fmlloader:?]
+[00:00:01] [main/INFO]: Stopping
+`
+
+func gzipString(s string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write(([]byte)(s))
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestAnalyzeInstance(t *testing.T) {
+	fsys := fstest.MapFS{
+		"mc/logs/latest.log":           {Data: ([]byte)(instanceLog)},
+		"mc/logs/debug.log":            {Data: ([]byte)(instanceLog)},
+		"mc/logs/2024-01-01-1.log.gz":  {Data: gzipString("[00:00:00] [main/INFO]: Nothing\n")},
+		"mc/hs_err_pid1.log":           {Data: ([]byte)("#\n# There is insufficient memory for the Java Runtime Environment to continue.\n#\n")},
+		"mc/config/forge-client.toml":  {Data: ([]byte)("")},
+		"mc/crash-reports/readme.html": {Data: ([]byte)("")},
+	}
+	files, err := FindInstanceFiles(fsys)
+	if err != nil {
+		t.Fatalf("FindInstanceFiles: %v", err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	expectNames := "mc/hs_err_pid1.log,mc/logs/latest.log,mc/logs/debug.log,mc/logs/2024-01-01-1.log.gz"
+	if got := strings.Join(names, ","); got != expectNames {
+		t.Fatalf("Expect files %s, got %s", expectNames, got)
+	}
+
+	a := NewAnalyzer(&mapErrorDB{})
+	report, err := a.AnalyzeInstance(context.Background(), files)
+	if err != nil {
+		t.Fatalf("AnalyzeInstance: %v", err)
+	}
+	if jc := report.Files[0].JVMCrash; jc == nil || !strings.HasPrefix(jc.Description, "There is insufficient memory") {
+		t.Errorf("Unexpected JVM crash log %#v", jc)
+	}
+	if latest := report.Files[1]; len(latest.Errors) != 1 || latest.Errors[0].File != "mc/logs/latest.log" {
+		t.Errorf("Expect 1 error in latest.log, got %d", len(latest.Errors))
+	}
+	if debug := report.Files[2]; len(debug.Errors) != 0 || debug.Duplicates != 1 {
+		t.Errorf("Expect the error in debug.log is a duplicate, got %d errors and %d duplicates", len(debug.Errors), debug.Duplicates)
+	}
+	if archived := report.Files[3]; archived.Failure != "" {
+		t.Errorf("Unexpected failure of the archived log: %s", archived.Failure)
+	}
+}
+
+func TestAnalyzeFileEncoding(t *testing.T) {
+	// the GBK bytes of the name are also valid UTF-8, so the encoding cannot be detected
+	report := statsCrashReport + "\tServer Name: 元图\n"
+	data, err := simplifiedchinese.GBK.NewEncoder().String(report)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	files, err := FindBatchFiles(fstest.MapFS{"crash.txt": {Data: ([]byte)(data)}})
+	if err != nil || len(files) != 1 {
+		t.Fatalf("FindBatchFiles: %d files, %v", len(files), err)
+	}
+	a := NewAnalyzer(&mapErrorDB{})
+	a.Encoding = "gbk"
+	res := a.AnalyzeFile(context.Background(), files[0])
+	if res.Failure != "" {
+		t.Fatalf("AnalyzeFile: %s", res.Failure)
+	}
+	if name := res.CrashReport.GetDetails("System Details").Details.Get("Server Name"); name != "元图" {
+		t.Errorf("Expect the details are decoded from GBK, got %q", name)
+	}
+}
+
+func TestInstanceFileTooLarge(t *testing.T) {
+	defer func(n int64) { MaxInstanceFileSize = n }(MaxInstanceFileSize)
+	MaxInstanceFileSize = 64
+	large := strings.Repeat("[00:00:00] [main/INFO]: Loading\n", 10)
+
+	files, err := FindInstanceFiles(fstest.MapFS{
+		"logs/latest.log":          {Data: ([]byte)("[00:00:00] [main/INFO]: Done\n")},
+		"logs/2024-01-01-1.log.gz": {Data: gzipString(large)},
+	})
+	if err != nil {
+		t.Fatalf("FindInstanceFiles: %v", err)
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	tw.WriteHeader(&tar.Header{Name: "logs/debug.log", Mode: 0644, Size: int64(len(large))})
+	io.WriteString(tw, large)
+	tw.Close()
+	tarFiles, err := FindTarFiles(&buf)
+	if err != nil {
+		t.Fatalf("FindTarFiles: %v", err)
+	}
+	files = append(files, tarFiles...)
+
+	report, err := NewAnalyzer(&mapErrorDB{}).AnalyzeInstance(context.Background(), files)
+	if err != nil {
+		t.Fatalf("AnalyzeInstance: %v", err)
+	}
+	failures := make(map[string]string)
+	for _, f := range report.Files {
+		failures[f.Name] = f.Failure
+	}
+	expect := map[string]string{
+		"logs/latest.log":          "",
+		"logs/2024-01-01-1.log.gz": ErrFileTooLarge.Error(),
+		"logs/debug.log":           ErrFileTooLarge.Error(),
+	}
+	if !maps.Equal(failures, expect) {
+		t.Errorf("Expect the large files fail, got %v", failures)
+	}
+}
@@ -96,8 +96,9 @@ func parseJavaError0(line string, sc *lineScanner) (je *JavaError) {
 	return
 }
 
-func scanJavaErrors(r io.Reader, cb func(*JavaError)) (err error) {
+func scanJavaErrors(r io.Reader, cb func(*JavaError), onWarning func(ScanWarning)) (err error) {
 	sc := newLineScanner(r)
+	sc.onWarning = onWarning
 	if !sc.Scan() {
 		return sc.Err()
 	}
@@ -148,20 +149,24 @@ func scanJavaErrors(r io.Reader, cb func(*JavaError)) (err error) {
 
 func ScanJavaErrors(r io.Reader) (res []*JavaError, err error) {
 	res = make([]*JavaError, 0, 3)
-	err = scanJavaErrors(r, func(je *JavaError) {
+	err = scanJavaErrors(decodeReader(r), func(je *JavaError) {
 		res = append(res, je)
-	})
+	}, nil)
 	return
 }
 
 func ScanJavaErrorsIntoChan(r io.Reader) (<-chan *JavaError, <-chan error) {
+	return scanJavaErrorsIntoChan(decodeReader(r), nil)
+}
+
+func scanJavaErrorsIntoChan(r io.Reader, onWarning func(ScanWarning)) (<-chan *JavaError, <-chan error) {
 	resCh := make(chan *JavaError, 3)
 	errCh := make(chan error, 0)
 	go func() {
 		defer close(resCh)
 		err := scanJavaErrors(r, func(je *JavaError) {
 			resCh <- je
-		})
+		}, onWarning)
 		if err != nil {
 			errCh <- err
 		}
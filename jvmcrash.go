@@ -0,0 +1,97 @@
+package mcla
+
+import (
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var ErrNotJVMCrashLog = errors.New("Not a JVM fatal error log")
+
+// JVMCrashLog is the header of the hs_err_pid<pid>.log, which is written when the JVM itself crashed.
+// It usually means a native library (e.g. the graphics driver) failed, or the memory is exhausted
+type JVMCrashLog struct {
+	// Description is the first paragraph of the header, without the leading '#'
+	Description string `json:"description"`
+	// Signal is the native error, e.g. EXCEPTION_ACCESS_VIOLATION or SIGSEGV
+	Signal           string `json:"signal,omitempty"`
+	JREVersion       string `json:"jreVersion,omitempty"`
+	JavaVM           string `json:"javaVM,omitempty"`
+	ProblematicFrame string `json:"problematicFrame,omitempty"`
+}
+
+var jvmSignalRe = regexp.MustCompile(`^([A-Z][A-Z0-9_]+) \(0x[0-9a-fA-F]+\)`)
+
+// Example:
+// ```
+// #
+// # A fatal error has been detected by the Java Runtime Environment:
+// #
+// #  EXCEPTION_ACCESS_VIOLATION (0xc0000005) at pc=0x00007ffb1c2a8d1e, pid=1234, tid=5678
+// #
+// # JRE version: OpenJDK Runtime Environment (17.0.8+7) (build 17.0.8+7-LTS)
+// # Java VM: OpenJDK 64-Bit Server VM (17.0.8+7-LTS, mixed mode, windows-amd64)
+// # Problematic frame:
+// # C  [atio6axx.dll+0x1a8d1e]
+// #
+// ```
+func ParseJVMCrashLog(r io.Reader) (log *JVMCrashLog, err error) {
+	sc := newLineScanner(decodeReader(r))
+	log = new(JVMCrashLog)
+	var (
+		header     bool
+		paragraphs [][]string
+		paragraph  []string
+	)
+	for sc.Scan() {
+		line, ok := strings.CutPrefix(sc.Text(), "#")
+		if !ok {
+			if header {
+				break
+			}
+			continue
+		}
+		header = true
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if paragraph != nil {
+				paragraphs = append(paragraphs, paragraph)
+				paragraph = nil
+			}
+			continue
+		}
+		paragraph = append(paragraph, line)
+	}
+	if err = sc.Err(); err != nil {
+		return nil, err
+	}
+	if paragraph != nil {
+		paragraphs = append(paragraphs, paragraph)
+	}
+	if len(paragraphs) == 0 {
+		return nil, ErrNotJVMCrashLog
+	}
+	for i, p := range paragraphs {
+		if i == 0 || (i == 1 && strings.HasPrefix(paragraphs[0][0], "A fatal error has been detected")) {
+			if log.Description != "" {
+				log.Description += "\n"
+			}
+			log.Description += strings.Join(p, "\n")
+		}
+		for j := 0; j < len(p); j++ {
+			line := p[j]
+			if m := jvmSignalRe.FindStringSubmatch(line); m != nil && log.Signal == "" {
+				log.Signal = m[1]
+			} else if v, ok := strings.CutPrefix(line, "JRE version:"); ok {
+				log.JREVersion = strings.TrimSpace(v)
+			} else if v, ok := strings.CutPrefix(line, "Java VM:"); ok {
+				log.JavaVM = strings.TrimSpace(v)
+			} else if line == "Problematic frame:" && j+1 < len(p) {
+				j++
+				log.ProblematicFrame = p[j]
+			}
+		}
+	}
+	return
+}
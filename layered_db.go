@@ -0,0 +1,119 @@
+package mcla
+
+import (
+	"errors"
+	"strconv"
+)
+
+var (
+	ErrUnknownDBLayer = errors.New("Unknown database layer")
+)
+
+const solutionIDLayerShift = 20
+
+// LayeredSolutionID namespaces a solution ID of the layer
+func LayeredSolutionID(layer int, id int) int {
+	return layer<<solutionIDLayerShift | id
+}
+
+// SplitLayeredSolutionID is the reverse of LayeredSolutionID
+func SplitLayeredSolutionID(id int) (layer int, local int) {
+	return id >> solutionIDLayerShift, id & (1<<solutionIDLayerShift - 1)
+}
+
+type DBLayer struct {
+	Name string
+	DB   ErrorDB
+}
+
+// LayeredErrorDB merges several ErrorDBs, layers added later have higher priority.
+// The base layer keeps its solution IDs as is, so the hard-coded solution IDs still work,
+// while solution IDs of the other layers are namespaced by LayeredSolutionID.
+type LayeredErrorDB struct {
+	layers []DBLayer
+}
+
+var _ ErrorDB = (*LayeredErrorDB)(nil)
+var _ SolutionIterator = (*LayeredErrorDB)(nil)
+
+func NewLayeredErrorDB(base DBLayer, overlays ...DBLayer) *LayeredErrorDB {
+	layers := make([]DBLayer, 0, 1+len(overlays))
+	layers = append(layers, base)
+	layers = append(layers, overlays...)
+	return &LayeredErrorDB{
+		layers: layers,
+	}
+}
+
+func (db *LayeredErrorDB) Layers() []DBLayer {
+	return db.layers
+}
+
+func errorRef(source string, id int) string {
+	return source + ":" + strconv.Itoa(id)
+}
+
+func (db *LayeredErrorDB) ForEachErrors(callback func(*ErrorDesc) error) (err error) {
+	overridden := make(map[string]struct{})
+	for i := len(db.layers) - 1; i >= 0; i-- {
+		layer := db.layers[i]
+		descs := make([]*ErrorDesc, 0, 64)
+		if err = layer.DB.ForEachErrors(func(e *ErrorDesc) error {
+			descs = append(descs, e)
+			return nil
+		}); err != nil {
+			return
+		}
+		for _, e := range descs {
+			for _, ref := range e.Overrides {
+				overridden[ref] = struct{}{}
+			}
+		}
+		for _, e := range descs {
+			if e.ID != 0 {
+				if _, ok := overridden[errorRef(layer.Name, e.ID)]; ok {
+					continue
+				}
+			}
+			if e.Error == "" && e.Message == "" && e.Pattern == "" {
+				continue
+			}
+			desc := *e
+			desc.Source = layer.Name
+			if i != 0 {
+				desc.Solutions = make([]int, len(e.Solutions))
+				for j, id := range e.Solutions {
+					desc.Solutions[j] = LayeredSolutionID(i, id)
+				}
+			}
+			if err = callback(&desc); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+func (db *LayeredErrorDB) GetSolution(id int) (sol *SolutionDesc, err error) {
+	layer, id := SplitLayeredSolutionID(id)
+	if layer >= len(db.layers) {
+		return nil, ErrUnknownDBLayer
+	}
+	return db.layers[layer].DB.GetSolution(id)
+}
+
+// ForEachSolutions iterates the solutions of the layers which implement SolutionIterator
+func (db *LayeredErrorDB) ForEachSolutions(callback func(id int, sol *SolutionDesc) error) (err error) {
+	for i, layer := range db.layers {
+		iter, ok := layer.DB.(SolutionIterator)
+		if !ok {
+			continue
+		}
+		if err = iter.ForEachSolutions(func(id int, sol *SolutionDesc) error {
+			return callback(LayeredSolutionID(i, id), sol)
+		}); err != nil {
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,71 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+)
+
+type mapErrorDB struct {
+	errors    []*ErrorDesc
+	solutions map[int]*SolutionDesc
+}
+
+func (db *mapErrorDB) ForEachErrors(callback func(*ErrorDesc) error) (err error) {
+	for _, e := range db.errors {
+		if err = callback(e); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (db *mapErrorDB) GetSolution(id int) (sol *SolutionDesc, err error) {
+	return db.solutions[id], nil
+}
+
+func TestLayeredErrorDB(t *testing.T) {
+	public := &mapErrorDB{
+		errors: []*ErrorDesc{
+			{ID: 1, Error: "java.lang.RuntimeException", Solutions: []int{1}},
+			{ID: 2, Error: "java.lang.NullPointerException", Solutions: []int{1}},
+		},
+		solutions: map[int]*SolutionDesc{1: {Description: "public"}},
+	}
+	team := &mapErrorDB{
+		errors: []*ErrorDesc{
+			{ID: 1, Error: "java.lang.IllegalStateException", Solutions: []int{1}},
+			{ID: 2, Overrides: []string{"public:2"}},
+		},
+		solutions: map[int]*SolutionDesc{1: {Description: "team"}},
+	}
+	db := NewLayeredErrorDB(DBLayer{"public", public}, DBLayer{"team", team})
+
+	var descs []*ErrorDesc
+	if err := db.ForEachErrors(func(e *ErrorDesc) error {
+		descs = append(descs, e)
+		return nil
+	}); err != nil {
+		t.Fatalf("ForEachErrors: %v", err)
+	}
+	if len(descs) != 2 {
+		t.Fatalf("Expect 2 errors, got %d", len(descs))
+	}
+	if e := descs[0]; e.Source != "team" || e.Error != "java.lang.IllegalStateException" {
+		t.Errorf("Expect the team entry first, got %s:%d", e.Source, e.ID)
+	}
+	if e := descs[1]; e.Source != "public" || e.ID != 1 {
+		t.Errorf("Expect public:1, got %s:%d", e.Source, e.ID)
+	}
+	for _, e := range descs {
+		sol, err := db.GetSolution(e.Solutions[0])
+		if err != nil {
+			t.Fatalf("GetSolution: %v", err)
+		}
+		if sol.Description != e.Source {
+			t.Errorf("Solution of %s:%d resolved to %q", e.Source, e.ID, sol.Description)
+		}
+	}
+	if team.errors[0].Solutions[0] != 1 {
+		t.Errorf("The source entry should not be modified")
+	}
+}
@@ -0,0 +1,76 @@
+package mcla
+
+import (
+	"fmt"
+	"strings"
+)
+
+const DefaultLocale = "en"
+
+// NormalizeLocale converts locales like "zh_CN.UTF-8" to "zh-CN"
+func NormalizeLocale(locale string) string {
+	locale, _ = split(locale, '.')
+	locale, _ = split(locale, '@')
+	if locale == "C" || locale == "POSIX" {
+		return ""
+	}
+	parts := strings.FieldsFunc(locale, func(r rune) bool { return r == '_' || r == '-' })
+	if len(parts) == 0 {
+		return ""
+	}
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		if len(parts[i]) == 4 { // script, e.g. Hans
+			parts[i] = strings.ToUpper(parts[i][:1]) + strings.ToLower(parts[i][1:])
+		} else {
+			parts[i] = strings.ToUpper(parts[i])
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// LocaleFallbacks returns the locales to try in order, e.g. "zh-Hans-CN" -> ["zh-Hans-CN", "zh-Hans", "zh"].
+// DefaultLocale is not added, since the default Description of a solution is used instead
+func LocaleFallbacks(locale string) (chain []string) {
+	locale = NormalizeLocale(locale)
+	for locale != "" {
+		chain = append(chain, locale)
+		locale, _ = rsplit(locale, '-')
+	}
+	return
+}
+
+// Localize returns a copy of the solution with the description in the locale,
+// the first available locale in LocaleFallbacks is used,
+// or the default Description is kept if none of them is available
+func (sol *SolutionDesc) Localize(locale string) *SolutionDesc {
+	res := *sol
+	for _, l := range LocaleFallbacks(locale) {
+		if desc, ok := sol.Descriptions[l]; ok && desc != "" {
+			res.Description = desc
+			break
+		}
+	}
+	return &res
+}
+
+// GetLocalizedSolution gets the solution from the database and localizes it,
+// the error wraps ErrEntryNotFound if the database returns no solution
+func GetLocalizedSolution(db ErrorDB, id int, locale string) (sol *SolutionDesc, err error) {
+	return localizeSolution(db.GetSolution, id, locale)
+}
+
+// GetLocalizedSolution is like the function GetLocalizedSolution, but the solution is cached by the analyzer
+func (a *Analyzer) GetLocalizedSolution(id int, locale string) (sol *SolutionDesc, err error) {
+	return localizeSolution(a.GetSolution, id, locale)
+}
+
+func localizeSolution(get func(id int) (*SolutionDesc, error), id int, locale string) (sol *SolutionDesc, err error) {
+	if sol, err = get(id); err != nil {
+		return
+	}
+	if sol == nil {
+		return nil, fmt.Errorf("Solution %d: %w", id, ErrEntryNotFound)
+	}
+	return sol.Localize(locale), nil
+}
@@ -0,0 +1,47 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"slices"
+	"testing"
+)
+
+func TestLocaleFallbacks(t *testing.T) {
+	cases := []struct {
+		locale string
+		expect []string
+	}{
+		{"zh_CN.UTF-8", []string{"zh-CN", "zh"}},
+		{"zh-hans-cn", []string{"zh-Hans-CN", "zh-Hans", "zh"}},
+		{"en_US", []string{"en-US", "en"}},
+		{"C", nil},
+		{"", nil},
+	}
+	for _, c := range cases {
+		if got := LocaleFallbacks(c.locale); !slices.Equal(got, c.expect) {
+			t.Errorf("LocaleFallbacks(%q) = %v, expect %v", c.locale, got, c.expect)
+		}
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	sol := &SolutionDesc{
+		Description: "default",
+		Descriptions: map[string]string{
+			"zh": "中文",
+			"en": "English",
+		},
+	}
+	if got := sol.Localize("zh_CN.UTF-8").Description; got != "中文" {
+		t.Errorf("Expect zh description, got %q", got)
+	}
+	if got := sol.Localize("en_US").Description; got != "English" {
+		t.Errorf("Expect en description, got %q", got)
+	}
+	if got := sol.Localize("ja-JP").Description; got != "default" {
+		t.Errorf("Expect the default description, got %q", got)
+	}
+	if sol.Description != "default" {
+		t.Errorf("The source solution should not be modified")
+	}
+}
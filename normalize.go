@@ -0,0 +1,97 @@
+package mcla
+
+import (
+	"regexp"
+	"strings"
+)
+
+// variableTokenRe matches the parts of a message that usually differ between two crashes
+var variableTokenRe = regexp.MustCompile(strings.Join([]string{
+	// quoted names, mod IDs
+	`'[^']*'`, `"[^"]*"`, `\[[^\]]*\]`,
+	// mixin configs
+	`[\w-]+\.(?:mixins|refmap)\.json`,
+	// UUIDs
+	`[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}`,
+	// object hashes, addresses
+	`@[0-9A-Fa-f]+\b`, `\b0x[0-9A-Fa-f]+\b`,
+	// paths, internal class names
+	`(?:[A-Za-z]:)?[\w.$-]*[\\/][^\s'":,()]*`,
+	// numbers, versions, coordinates
+	`-?\b\d+(?:\.\d+)*\b`,
+}, "|"))
+
+// GeneralizeMessage drafts the ErrorDesc message or pattern from the first line of the message,
+// so it matches the similar errors. Only one of them is returned:
+//   - message is the line itself if it has no variable part,
+//     or the literal prefix with the ` *` wildcard if the variable parts are all at the end
+//   - pattern is a regexp of the line with each variable part replaced by `.+`
+//
+// Both are empty if the line has no literal text.
+func GeneralizeMessage(msg string) (message string, pattern string) {
+	msg, _ = split(msg, '\n')
+	msg = strings.TrimSpace(msg)
+	locs := variableTokenRe.FindAllStringIndex(msg, -1)
+	if locs == nil {
+		return msg, ""
+	}
+	prefix := strings.TrimRight(msg[:locs[0][0]], " \t")
+	tail := strings.TrimSpace(variableTokenRe.ReplaceAllString(msg[locs[0][0]:], ""))
+	if tail == "" {
+		if prefix == "" {
+			return "", ""
+		}
+		return prefix + " *", ""
+	}
+	var sb strings.Builder
+	sb.WriteByte('^')
+	last := 0
+	for _, loc := range locs {
+		sb.WriteString(regexp.QuoteMeta(msg[last:loc[0]]))
+		sb.WriteString(".+")
+		last = loc[1]
+	}
+	sb.WriteString(regexp.QuoteMeta(msg[last:]))
+	return "", sb.String()
+}
+
+// NormalizeMessage replaces the variable parts in the first line of the message with `*`,
+// so the messages of the same error from different crashes are equal
+func NormalizeMessage(msg string) string {
+	msg, _ = split(msg, '\n')
+	return variableTokenRe.ReplaceAllString(strings.TrimSpace(msg), "*")
+}
+
+// RootCause returns the last error in the cause chain
+func (je *JavaError) RootCause() *JavaError {
+	for je.CausedBy != nil {
+		je = je.CausedBy
+	}
+	return je
+}
+
+// Fingerprint identifies the same error from different logs and crash reports.
+// It consists of the classes and the normalized messages of the cause chain,
+// and the first suspect frame of the root cause
+func Fingerprint(jerr *JavaError) string {
+	var sb strings.Builder
+	for e := jerr; e != nil; e = e.CausedBy {
+		if e != jerr {
+			sb.WriteString(" <- ")
+		}
+		sb.WriteString(e.Class)
+		if msg := NormalizeMessage(e.Message); msg != "" {
+			sb.WriteString(": ")
+			sb.WriteString(msg)
+		}
+	}
+	root := jerr.RootCause()
+	if frames := root.Stacktrace.SuspectFrames(); len(frames) > 0 {
+		s := root.Stacktrace[frames[0]]
+		sb.WriteString(" @ ")
+		sb.WriteString(s.Class)
+		sb.WriteByte('.')
+		sb.WriteString(s.Method)
+	}
+	return sb.String()
+}
@@ -0,0 +1,26 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+)
+
+func TestGeneralizeMessage(t *testing.T) {
+	cases := []struct {
+		msg, message, pattern string
+	}{
+		{"null", "null", ""},
+		{"Attempted to load class net/minecraft/client/Minecraft for invalid dist DEDICATED_SERVER", "", "^Attempted to load class .+ for invalid dist DEDICATED_SERVER"},
+		{"Mod 'create' requires 'flywheel' 0.6.10 or above", "", "^Mod .+ requires .+ .+ or above"},
+		{"Index 5 out of bounds for length 5", "", "^Index .+ out of bounds for length .+"},
+		{"Cannot invoke \"Object.toString()\" because \"x\" is null", "", "^Cannot invoke .+ because .+ is null"},
+		{"'minecraft:air' is not a valid item (Item.java)", "", `^.+ is not a valid item \(Item\.java\)`},
+		{"Failed to read C:\\Users\\steve\\.minecraft\\options.txt\nat line 2", "Failed to read *", ""},
+		{"1234", "", ""},
+	}
+	for _, c := range cases {
+		if message, pattern := GeneralizeMessage(c.msg); message != c.message || pattern != c.pattern {
+			t.Errorf("GeneralizeMessage(%q) = %q, %q, expect %q, %q", c.msg, message, pattern, c.message, c.pattern)
+		}
+	}
+}
@@ -0,0 +1,369 @@
+package mcla
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// RedactKind is the category of the redacted information, it's used as the name of the placeholders
+type RedactKind string
+
+const (
+	RedactUsername RedactKind = "username"
+	RedactUUID     RedactKind = "uuid"
+	RedactToken    RedactKind = "token"
+	RedactIP       RedactKind = "ip"
+	RedactHomeUser RedactKind = "home"
+	RedactWorld    RedactKind = "world"
+)
+
+var (
+	// the values of the launch arguments which identify the player
+	launchArgRe = regexp.MustCompile(`(--(?:accessToken|session|clientId|xuid|uuid|username))(\s+|=)("[^"]*"|[^\s,\]]+)`)
+	// the legacy session format, `token:<access token>:<uuid>`
+	sessionTokenRe = regexp.MustCompile(`\btoken:[\w.-]{16,}(?::[0-9a-fA-F]{32})?`)
+	// JSON web tokens, which are used as Microsoft access tokens
+	jwtRe  = regexp.MustCompile(`\beyJ[\w-]{8,}\.[\w-]{8,}\.[\w-]+`)
+	uuidRe = regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`)
+	// IPv4 addresses look like the 4-part versions, so the address context is captured,
+	// e.g. `/1.2.3.4`, `user@1.2.3.4`, `host: 1.2.3.4`, `Connecting to 1.2.3.4` or `1.2.3.4:25565`
+	ipv4Re = regexp.MustCompile(`(/|@|(?i:\b(?:host(?:name)?|address|ip|connecting to))[\s:=]+)?\b((?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)(?:\.(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)){3})\b(:\d{1,5}\b)?`)
+	// the lines of mod lists, which are full of versions,
+	// e.g. `jei-1.20.1-forge-15.2.0.27.jar |Just Enough Items |jei |15.2.0.27 |DONE` or `jei: Just Enough Items 15.2.0.27`
+	modListLineRe = regexp.MustCompile(`\||\.jar\b|^\s*[a-z][\w-]*: \S`)
+	ipv6Re        = regexp.MustCompile(`\b(?:[0-9a-fA-F]{1,4}:){7}[0-9a-fA-F]{1,4}\b|\b(?:[0-9a-fA-F]{1,4}:){1,6}:[0-9a-fA-F]{1,4}(?::[0-9a-fA-F]{1,4})*\b`)
+	// the user name segment in home directories, e.g. `C:\Users\<name>\`, `/home/<name>/`
+	homePathRe = regexp.MustCompile(`(?i)((?:[a-z]:)?[\\/]+(?:users|documents and settings)[\\/]+|/home/|/Users/)([^\\/\s"':;]+)`)
+
+	// the logs which tell the player names
+	usernameLearnRes = []*regexp.Regexp{
+		regexp.MustCompile(`Setting user: ([\w]{2,16})\b`),
+		regexp.MustCompile(`--username(?:\s+|=)"?([\w]{2,16})\b`),
+		regexp.MustCompile(`\b([\w]{2,16})\[/[^\]]+\] logged in with entity id`),
+		regexp.MustCompile(`\b([\w]{2,16}) (?:joined|left) the game`),
+		regexp.MustCompile(`UUID of player ([\w]{2,16}) is`),
+	}
+	// the logs which tell the world names
+	worldLearnRes = []*regexp.Regexp{
+		regexp.MustCompile(`Preparing level "([^"]+)"`),
+		regexp.MustCompile(`ServerLevel\[([^\]]+)\]`),
+		regexp.MustCompile(`(?i)Level name: (.+?)\s*$`),
+		regexp.MustCompile(`[\\/]saves[\\/]+([^\\/"]+)`),
+	}
+)
+
+// keptIPs are not personal information, and they are useful when diagnosing network issues
+var keptIPs = []string{"127.0.0.1", "0.0.0.0", "255.255.255.255", "::1"}
+
+// Redactor replaces the personal information in logs with stable placeholders like `<username-1>`,
+// the same value is always replaced by the same placeholder.
+// It never adds or removes line breaks, so the line numbers of the redacted text still line up
+type Redactor struct {
+	// Usernames are the extra names to redact, e.g. the names of other players
+	Usernames []string
+
+	placeholders map[string]string // value -> placeholder
+	counts       map[RedactKind]int
+	usernames    []string
+	worlds       []string
+}
+
+func NewRedactor() *Redactor {
+	return &Redactor{
+		placeholders: make(map[string]string),
+		counts:       make(map[RedactKind]int),
+	}
+}
+
+func (r *Redactor) placeholder(kind RedactKind, value string) string {
+	if kind == RedactUUID {
+		// the UUIDs in the launch arguments are not dashed
+		value = strings.ToLower(strings.ReplaceAll(value, "-", ""))
+	}
+	key := (string)(kind) + "\x00" + value
+	if p, ok := r.placeholders[key]; ok {
+		return p
+	}
+	r.counts[kind]++
+	p := fmt.Sprintf("<%s-%d>", kind, r.counts[kind])
+	r.placeholders[key] = p
+	return p
+}
+
+// Counts returns how many distinct values of each kind were redacted
+func (r *Redactor) Counts() map[RedactKind]int {
+	return r.counts
+}
+
+func addUnique(list []string, value string) []string {
+	if value == "" || slices.Contains(list, value) {
+		return list
+	}
+	return append(list, value)
+}
+
+// Learn collects the usernames and the world names in the text,
+// so their occurrences before the line which tells them can also be redacted
+func (r *Redactor) Learn(text string) {
+	for _, re := range usernameLearnRes {
+		for _, m := range re.FindAllStringSubmatch(text, -1) {
+			r.usernames = addUnique(r.usernames, m[1])
+		}
+	}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		for _, re := range worldLearnRes {
+			for _, m := range re.FindAllStringSubmatch(line, -1) {
+				r.worlds = addUnique(r.worlds, strings.TrimSpace(m[1]))
+			}
+		}
+	}
+}
+
+func isNameChar(b byte) bool {
+	return b == '_' || b == '.' || b == '$' || b == '/' || b == '\\' ||
+		'0' <= b && b <= '9' || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z'
+}
+
+// replaceName replaces the name when it's not a part of another name or a qualified class name
+func (r *Redactor) replaceName(s string, name string, kind RedactKind) string {
+	if len(name) < 3 {
+		return s
+	}
+	var sb strings.Builder
+	for {
+		i := strings.Index(s, name)
+		if i < 0 {
+			break
+		}
+		j := i + len(name)
+		if (i > 0 && isNameChar(s[i-1])) || (j < len(s) && isNameChar(s[j]) && s[j] != '.') {
+			sb.WriteString(s[:j])
+		} else {
+			sb.WriteString(s[:i])
+			sb.WriteString(r.placeholder(kind, name))
+		}
+		s = s[j:]
+	}
+	sb.WriteString(s)
+	return sb.String()
+}
+
+// replaceQuoted replaces the name only when it's quoted or bracketed,
+// since world names like "world" are common words
+func (r *Redactor) replaceQuoted(s string, name string, kind RedactKind) string {
+	for _, q := range [][2]string{{`"`, `"`}, {`'`, `'`}, {`[`, `]`}, {`: `, ""}} {
+		quoted := q[0] + name + q[1]
+		if q[1] == "" {
+			// `Level name: <name>` at the end of the line
+			if strings.HasSuffix(s, quoted) {
+				s = s[:len(s)-len(name)] + r.placeholder(kind, name)
+			}
+			continue
+		}
+		s = strings.ReplaceAll(s, quoted, q[0]+r.placeholder(kind, name)+q[1])
+	}
+	for _, sep := range []string{"/saves/", `\saves\`} {
+		s = strings.ReplaceAll(s, sep+name, sep+r.placeholder(kind, name))
+	}
+	return s
+}
+
+// isVersionContext reports whether the bare IPv4 match text[i:j] is a part of a version,
+// e.g. `forge-1.2.3.4`, `1.2.3.4-beta` or `1.2.3.4.jar`
+func isVersionContext(text string, i, j int) bool {
+	if i > 0 && strings.IndexByte("-+|", text[i-1]) >= 0 {
+		return true
+	}
+	return j < len(text) && strings.IndexByte("-+.", text[j]) >= 0
+}
+
+// redactIPv4 redacts the IPv4 addresses in the line.
+// The addresses without any address context are kept when they may be versions,
+// or bare is false
+func (r *Redactor) redactIPv4(line string, bare bool) string {
+	matches := ipv4Re.FindAllStringSubmatchIndex(line, -1)
+	if len(matches) == 0 {
+		return line
+	}
+	bare = bare && !modListLineRe.MatchString(line)
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		i, j := m[4], m[5]
+		ip := line[i:j]
+		if slices.Contains(keptIPs, ip) {
+			continue
+		}
+		if m[2] < 0 && m[6] < 0 && (!bare || isVersionContext(line, i, j)) {
+			continue
+		}
+		sb.WriteString(line[last:i])
+		sb.WriteString(r.placeholder(RedactIP, ip))
+		last = j
+	}
+	sb.WriteString(line[last:])
+	return sb.String()
+}
+
+// RedactString redacts the text, the usernames and world names must be learned before
+func (r *Redactor) RedactString(text string) string {
+	return r.redactString(text, true)
+}
+
+// redactString redacts the text, the IPv4 addresses without address context are only redacted when bare is true
+func (r *Redactor) redactString(text string, bare bool) string {
+	text = launchArgRe.ReplaceAllStringFunc(text, func(m string) string {
+		g := launchArgRe.FindStringSubmatch(m)
+		kind := RedactToken
+		switch g[1] {
+		case "--uuid":
+			kind = RedactUUID
+		case "--username":
+			kind = RedactUsername
+		}
+		return g[1] + g[2] + r.placeholder(kind, strings.Trim(g[3], `"`))
+	})
+	text = sessionTokenRe.ReplaceAllStringFunc(text, func(m string) string { return r.placeholder(RedactToken, m) })
+	text = jwtRe.ReplaceAllStringFunc(text, func(m string) string { return r.placeholder(RedactToken, m) })
+	text = uuidRe.ReplaceAllStringFunc(text, func(m string) string { return r.placeholder(RedactUUID, m) })
+	text = homePathRe.ReplaceAllStringFunc(text, func(m string) string {
+		g := homePathRe.FindStringSubmatch(m)
+		return g[1] + r.placeholder(RedactHomeUser, g[2])
+	})
+	text = ipv6Re.ReplaceAllStringFunc(text, func(m string) string {
+		if slices.Contains(keptIPs, m) {
+			return m
+		}
+		return r.placeholder(RedactIP, m)
+	})
+	for _, name := range r.Usernames {
+		text = r.replaceName(text, name, RedactUsername)
+	}
+	for _, name := range r.usernames {
+		text = r.replaceName(text, name, RedactUsername)
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		cr := strings.HasSuffix(line, "\r")
+		line = strings.TrimSuffix(line, "\r")
+		line = r.redactIPv4(line, bare)
+		for _, world := range r.worlds {
+			line = r.replaceQuoted(line, world, RedactWorld)
+		}
+		if cr {
+			line += "\r"
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// RedactLog learns the whole log first, then writes the redacted log with the original line endings
+func (r *Redactor) RedactLog(w io.Writer, rd io.Reader) (err error) {
+	buf, err := io.ReadAll(rd)
+	if err != nil {
+		return
+	}
+	text := (string)(buf)
+	r.Learn(text)
+	br := bufio.NewWriter(w)
+	for len(text) > 0 {
+		line := text
+		if i := strings.IndexByte(text, '\n'); i >= 0 {
+			line = text[:i+1]
+		}
+		text = text[len(line):]
+		if _, err = br.WriteString(r.RedactString(line)); err != nil {
+			return
+		}
+	}
+	return br.Flush()
+}
+
+func (r *Redactor) redactJavaError(jerr *JavaError) *JavaError {
+	if jerr == nil {
+		return nil
+	}
+	res := *jerr
+	res.Message = r.RedactString(jerr.Message)
+	res.Stacktrace = r.redactStacktrace(jerr.Stacktrace)
+	res.CausedBy = r.redactJavaError(jerr.CausedBy)
+	return &res
+}
+
+func (r *Redactor) redactStacktrace(st Stacktrace) Stacktrace {
+	if st == nil {
+		return nil
+	}
+	res := make(Stacktrace, len(st))
+	for i, s := range st {
+		s.Raw = r.RedactString(s.Raw)
+		res[i] = s
+	}
+	return res
+}
+
+func (r *Redactor) redactDetails(d ReportDetails) ReportDetails {
+	if d == nil {
+		return nil
+	}
+	res := make(ReportDetails, len(d))
+	for key, values := range d {
+		// the mod lists like `Mod List` and `Fabric Mods` are full of versions
+		bare := !strings.Contains(key, "MOD")
+		redacted := make([]string, len(values))
+		for i, v := range values {
+			redacted[i] = r.redactString(v, bare)
+		}
+		res[key] = redacted
+	}
+	return res
+}
+
+func learnJavaError(r *Redactor, jerr *JavaError) {
+	for ; jerr != nil; jerr = jerr.CausedBy {
+		r.Learn(jerr.Message)
+	}
+}
+
+func learnDetails(r *Redactor, d ReportDetails) {
+	for key, values := range d {
+		for _, v := range values {
+			r.Learn(key + ": " + v)
+		}
+	}
+}
+
+// RedactCrashReport returns a redacted copy of the report, the report itself is not modified
+func (r *Redactor) RedactCrashReport(report *CrashReport) *CrashReport {
+	r.Learn(report.Description)
+	learnJavaError(r, report.Error)
+	r.Learn(report.HeadThread.Thread)
+	learnDetails(r, report.AffectedLevel.Details)
+	for _, item := range report.OtherDetails {
+		learnDetails(r, item.Details)
+	}
+
+	res := &CrashReport{
+		Description: r.RedactString(report.Description),
+		Error:       r.redactJavaError(report.Error),
+		HeadThread: HeadThread{
+			Thread:     r.RedactString(report.HeadThread.Thread),
+			Stacktrace: r.redactStacktrace(report.HeadThread.Stacktrace),
+		},
+		AffectedLevel: AffectedLevel{
+			Details:    r.redactDetails(report.AffectedLevel.Details),
+			Stacktrace: r.redactStacktrace(report.AffectedLevel.Stacktrace),
+		},
+		OtherDetails: make(map[string]DetailsItem, len(report.OtherDetails)),
+	}
+	for name, item := range report.OtherDetails {
+		res.OtherDetails[name] = DetailsItem{r.redactDetails(item.Details)}
+	}
+	return res
+}
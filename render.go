@@ -0,0 +1,140 @@
+package mcla
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+type RenderFormat string
+
+const (
+	RenderText     RenderFormat = "text"
+	RenderMarkdown RenderFormat = "markdown"
+	RenderHTML     RenderFormat = "html"
+)
+
+func ParseRenderFormat(s string) (f RenderFormat, err error) {
+	switch f = (RenderFormat)(strings.ToLower(s)); f {
+	case RenderText, RenderMarkdown, RenderHTML:
+		return
+	case "md":
+		return RenderMarkdown, nil
+	case "", "plain":
+		return RenderText, nil
+	}
+	return "", fmt.Errorf("Unknown render format %q", s)
+}
+
+var placeholderRe = regexp.MustCompile(`\$\{([\w.-]+)\}`)
+
+// ExpandPlaceholders replaces `${name}` in s with the value in data,
+// escape is applied to the values, and unknown placeholders are kept as is
+func ExpandPlaceholders(s string, data map[string]any, escape func(string) string) string {
+	if len(data) == 0 {
+		return s
+	}
+	return placeholderRe.ReplaceAllStringFunc(s, func(m string) string {
+		v, ok := data[m[2:len(m)-1]]
+		if !ok {
+			return m
+		}
+		str := fmt.Sprint(v)
+		if escape != nil {
+			str = escape(str)
+		}
+		return str
+	})
+}
+
+// expandLink expands the placeholders of a link, the values in the query are escaped by url.QueryEscape,
+// and the values in the path and the fragment by url.PathEscape, so a space is `+` only in the query
+func expandLink(link string, data map[string]any) string {
+	if len(data) == 0 {
+		return link
+	}
+	query, fragment := strings.IndexByte(link, '?'), strings.IndexByte(link, '#')
+	if fragment < 0 {
+		fragment = len(link)
+	}
+	if query > fragment {
+		query = -1
+	}
+	var sb strings.Builder
+	last := 0
+	for _, m := range placeholderRe.FindAllStringSubmatchIndex(link, -1) {
+		v, ok := data[link[m[2]:m[3]]]
+		if !ok {
+			continue
+		}
+		sb.WriteString(link[last:m[0]])
+		if query >= 0 && query < m[0] && m[0] < fragment {
+			sb.WriteString(url.QueryEscape(fmt.Sprint(v)))
+		} else {
+			sb.WriteString(url.PathEscape(fmt.Sprint(v)))
+		}
+		last = m[1]
+	}
+	sb.WriteString(link[last:])
+	return sb.String()
+}
+
+// ExpandSolution returns a copy of the solution with the placeholders expanded
+func ExpandSolution(sol *SolutionDesc, data map[string]any) *SolutionDesc {
+	res := *sol
+	res.Description = ExpandPlaceholders(sol.Description, data, nil)
+	res.LinkTo = expandLink(sol.LinkTo, data)
+	return &res
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`,
+	`<`, `\<`, `>`, `\>`, `#`, `\#`, `|`, `\|`,
+)
+
+// isWebLink reports whether the link is an absolute http or https URL,
+// the other schemes like `javascript:` must not be rendered as clickable links
+func isWebLink(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	scheme := strings.ToLower(u.Scheme)
+	return (scheme == "http" || scheme == "https") && u.Host != ""
+}
+
+// RenderSolution expands the placeholders with data and formats the solution.
+// For markdown and HTML, the link is omitted if it's not a http(s) URL
+func RenderSolution(sol *SolutionDesc, data map[string]any, format RenderFormat) string {
+	link := expandLink(sol.LinkTo, data)
+	if format != RenderText && !isWebLink(link) {
+		link = ""
+	}
+	var sb strings.Builder
+	switch format {
+	case RenderMarkdown:
+		sb.WriteString(ExpandPlaceholders(sol.Description, data, markdownEscaper.Replace))
+		if link != "" {
+			fmt.Fprintf(&sb, "\n\n<%s>", link)
+		}
+	case RenderHTML:
+		sb.WriteString(`<div class="mcla-solution"><p>`)
+		desc := ExpandPlaceholders(html.EscapeString(sol.Description), data, html.EscapeString)
+		sb.WriteString(strings.ReplaceAll(desc, "\n", "<br/>"))
+		sb.WriteString(`</p>`)
+		if link != "" {
+			link = html.EscapeString(link)
+			fmt.Fprintf(&sb, `<a href="%s" target="_blank" rel="noopener noreferrer">%s</a>`, link, link)
+		}
+		sb.WriteString(`</div>`)
+	default:
+		sb.WriteString(ExpandPlaceholders(sol.Description, data, nil))
+		if link != "" {
+			sb.WriteString("\n")
+			sb.WriteString(link)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,71 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+)
+
+func TestRenderSolution(t *testing.T) {
+	sol := &SolutionDesc{
+		Description: "${mod1} conflicts with ${mod2} <${unknown}>",
+		LinkTo:      "https://modrinth.com/mods?q=${mod1}",
+	}
+	data := map[string]any{"mod1": "tfc", "mod2": "serene seasons"}
+	cases := []struct {
+		format RenderFormat
+		expect string
+	}{
+		{RenderText, "tfc conflicts with serene seasons <${unknown}>\nhttps://modrinth.com/mods?q=tfc"},
+		{RenderMarkdown, "tfc conflicts with serene seasons <${unknown}>\n\n<https://modrinth.com/mods?q=tfc>"},
+		{RenderHTML, `<div class="mcla-solution"><p>tfc conflicts with serene seasons &lt;${unknown}&gt;</p>` +
+			`<a href="https://modrinth.com/mods?q=tfc" target="_blank" rel="noopener noreferrer">https://modrinth.com/mods?q=tfc</a></div>`},
+	}
+	for _, c := range cases {
+		if got := RenderSolution(sol, data, c.format); got != c.expect {
+			t.Errorf("RenderSolution(%s):\n got %q\nwant %q", c.format, got, c.expect)
+		}
+	}
+}
+
+func TestRenderSolutionUnsafeLink(t *testing.T) {
+	for _, link := range []string{"javascript:alert(1)", "JavaScript:alert(1)", "data:text/html,<script>", "//example.com"} {
+		sol := &SolutionDesc{Description: "Update the mod", LinkTo: link}
+		if got := RenderSolution(sol, nil, RenderHTML); got != `<div class="mcla-solution"><p>Update the mod</p></div>` {
+			t.Errorf("Expect the link %q is omitted in HTML, got %q", link, got)
+		}
+		if got := RenderSolution(sol, nil, RenderMarkdown); got != "Update the mod" {
+			t.Errorf("Expect the link %q is omitted in markdown, got %q", link, got)
+		}
+	}
+}
+
+func TestPatternCaptures(t *testing.T) {
+	db := &mapErrorDB{
+		errors: []*ErrorDesc{
+			{ID: 1, Error: "java.lang.RuntimeException", Pattern: `^Mod (?P<mod>\w+) requires (\w+)`, Solutions: []int{1}},
+		},
+	}
+	a := NewAnalyzer(db)
+	matched, err := a.DoError(&JavaError{
+		Class:   "java.lang.RuntimeException",
+		Message: "Mod create requires flywheel 0.6.10 or above",
+	})
+	if err != nil {
+		t.Fatalf("DoError: %v", err)
+	}
+	if len(matched) != 1 || matched[0].Match != 1 {
+		t.Fatalf("Expect a full match, got %v", matched)
+	}
+	data := matched[0].TemplateData()
+	if data["mod"] != "create" || data["2"] != "flywheel" {
+		t.Errorf("Unexpected captured data %v", data)
+	}
+}
+
+func TestExpandSolutionLink(t *testing.T) {
+	sol := &SolutionDesc{LinkTo: "https://example.com/wiki/${mod}/issues?q=${message}#${mod}"}
+	res := ExpandSolution(sol, map[string]any{"mod": "serene seasons", "message": "a b&c"})
+	if expect := "https://example.com/wiki/serene%20seasons/issues?q=a+b%26c#serene%20seasons"; res.LinkTo != expect {
+		t.Errorf("Unexpected link:\n got %q\nwant %q", res.LinkTo, expect)
+	}
+}
@@ -0,0 +1,12 @@
+package mcla_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/GlobeMC/mcla/ghdb/dbtest"
+)
+
+func TestDBSamples(t *testing.T) {
+	dbtest.RunSamples(t, os.DirFS("testdata/db"), 0)
+}
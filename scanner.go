@@ -2,23 +2,89 @@ package mcla
 
 import (
 	"bufio"
+	"bytes"
+	"fmt"
 	"io"
 )
 
+// MaxLineSize is the max length of a line, the longer lines like huge NBT dumps are truncated
+const MaxLineSize = 1024 * 1024
+
+// maxScanWarnings limits the warnings of a log, since a large binary file may have a warning on every line
+const maxScanWarnings = 100
+
+// ScanWarning is a problem of a line which is tolerated when scanning a log
+type ScanWarning struct {
+	LineNo  int    `json:"lineNo"`
+	Message string `json:"message"`
+}
+
 type lineScanner struct {
 	count int
 	*bufio.Scanner
+
+	// truncated is set when the current line is longer than MaxLineSize, and the rest of it is being skipped
+	truncated bool
+	inBinary  bool
+	warnings  int
+	onWarning func(ScanWarning)
 }
 
 func newLineScanner(r io.Reader) *lineScanner {
-	bs := bufio.NewScanner(r)
-	bs.Buffer(make([]byte, 16*1024), 1024*1024) // 1MB per line, large enough?
-	return &lineScanner{
-		count:   0,
-		Scanner: bs,
+	s := &lineScanner{
+		count: 0,
+	}
+	s.Scanner = bufio.NewScanner(r)
+	s.Scanner.Buffer(make([]byte, 16*1024), MaxLineSize)
+	s.Scanner.Split(s.splitLines)
+	return s
+}
+
+// splitLines is bufio.ScanLines, but a line longer than MaxLineSize is truncated instead of failing
+func (s *lineScanner) splitLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	i := bytes.IndexByte(data, '\n')
+	if s.truncated {
+		if i < 0 {
+			return len(data), nil, nil
+		}
+		s.truncated = false
+		return i + 1, nil, nil
+	}
+	switch {
+	case i >= 0:
+		return i + 1, bytes.TrimSuffix(data[:i], []byte{'\r'}), nil
+	case len(data) >= MaxLineSize:
+		s.truncated = !atEOF
+		s.warn(s.count+1, fmt.Sprintf("Line is longer than %d bytes, truncated", MaxLineSize))
+		return len(data), data[:MaxLineSize], nil
+	case atEOF:
+		return len(data), bytes.TrimSuffix(data, []byte{'\r'}), nil
+	}
+	return 0, nil, nil
+}
+
+func (s *lineScanner) warn(lineNo int, msg string) {
+	if s.onWarning == nil {
+		return
+	}
+	s.warnings++
+	switch {
+	case s.warnings < maxScanWarnings:
+		s.onWarning(ScanWarning{LineNo: lineNo, Message: msg})
+	case s.warnings == maxScanWarnings:
+		s.onWarning(ScanWarning{LineNo: lineNo, Message: "Too many warnings, the rest are omitted"})
 	}
 }
 
+// isControl reports whether the byte is a control character which should not be in a text log,
+// the tabs and the escapes of ANSI colors are allowed
+func isControl(b byte) bool {
+	return b < ' ' && b != '\t' && b != '\x1b' || b == 0x7f
+}
+
 func (s *lineScanner) Scan() bool {
 	if !s.Scanner.Scan() {
 		return false
@@ -27,6 +93,45 @@ func (s *lineScanner) Scan() bool {
 	return true
 }
 
+// Bytes returns the current line, the lines of binary data are returned as empty lines,
+// and the control characters like NUL and the ANSI color codes are removed from the other lines
+func (s *lineScanner) Bytes() []byte {
+	line := s.Scanner.Bytes()
+	controls := 0
+	for _, b := range line {
+		if isControl(b) {
+			controls++
+		}
+	}
+	if controls == 0 {
+		s.inBinary = false
+		if bytes.IndexByte(line, '\x1b') >= 0 {
+			return ansiEscapeRe.ReplaceAll(line, nil)
+		}
+		return line
+	}
+	// the text in legacy encodings is not valid UTF-8, so only the control characters are counted
+	if controls*4 >= len(line) {
+		if !s.inBinary {
+			s.inBinary = true
+			s.warn(s.count, "Binary data is skipped")
+		}
+		return nil
+	}
+	s.inBinary = false
+	text := make([]byte, 0, len(line)-controls)
+	for _, b := range line {
+		if !isControl(b) {
+			text = append(text, b)
+		}
+	}
+	return ansiEscapeRe.ReplaceAll(text, nil)
+}
+
+func (s *lineScanner) Text() string {
+	return (string)(s.Bytes())
+}
+
 func (s *lineScanner) Count() int {
 	return s.count
 }
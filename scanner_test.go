@@ -0,0 +1,61 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"context"
+	"strings"
+)
+
+func garbageLog() string {
+	const jerr = "java.lang.IllegalStateException: Bad state\n\tat com.example.mod.Mob.tick(Mob.java:1)\n"
+	return "[00:00:00] [main/INFO]: Starting\n" +
+		jerr +
+		"[00:00:01] [main/INFO]: " + strings.Repeat("A", MaxLineSize*2+100) + "\n" +
+		strings.Repeat("\x00", 4096) + "\n" +
+		"\x00\x01\x02\x03binary\x04\x05\x06\x07\n" +
+		"[00:00:02] [main/INFO]: Nul\x00 inside\n" +
+		jerr
+}
+
+func TestScanJavaErrorsGarbage(t *testing.T) {
+	errs, err := ScanJavaErrors(strings.NewReader(garbageLog()))
+	if err != nil {
+		t.Fatalf("ScanJavaErrors: %v", err)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("Expect 2 errors, got %d", len(errs))
+	}
+	if errs[0].LineNo != 2 || errs[1].LineNo != 8 {
+		t.Errorf("Expect the errors at line 2 and 8, got %d and %d", errs[0].LineNo, errs[1].LineNo)
+	}
+}
+
+func TestDoLogStreamWarnings(t *testing.T) {
+	a := NewAnalyzer(&mapErrorDB{})
+	result, ctx := a.DoLogStream(context.Background(), strings.NewReader(garbageLog()))
+	count := 0
+	for res := range result {
+		if res == nil {
+			break
+		}
+		count++
+	}
+	if err := context.Cause(ctx); err != nil {
+		t.Fatalf("DoLogStream: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expect 2 errors, got %d", count)
+	}
+	warnings := LogWarnings(ctx)
+	if len(warnings) != 2 {
+		t.Fatalf("Expect 2 warnings, got %v", warnings)
+	}
+	if warnings[0].LineNo != 4 || !strings.Contains(warnings[0].Message, "truncated") {
+		t.Errorf("Unexpected warning %v", warnings[0])
+	}
+	if warnings[1].LineNo != 5 || !strings.Contains(warnings[1].Message, "Binary") {
+		t.Errorf("Unexpected warning %v", warnings[1])
+	}
+}
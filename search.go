@@ -0,0 +1,243 @@
+package mcla
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// SolutionIterator is implemented by the ErrorDBs which can list all of their solutions
+type SolutionIterator interface {
+	ForEachSolutions(callback func(id int, sol *SolutionDesc) error) (err error)
+}
+
+type SearchQuery struct {
+	// Text is matched against the errors' class, message, pattern and the solutions' descriptions, tags and link
+	Text string `json:"text"`
+	// Tags that a solution must have all of them
+	Tags []string `json:"tags"`
+	// Class is the exception class, the solutions must be referred by an error of the class
+	Class string `json:"class"`
+	// Limit is the max number of solutions to return, 0 means no limit
+	Limit int `json:"limit"`
+}
+
+type SolutionHit struct {
+	ID       int           `json:"id"`
+	Score    float32       `json:"score"`
+	Solution *SolutionDesc `json:"solution"`
+	// Errors are the matched errors which refer to the solution
+	Errors []*ErrorDesc `json:"errors"`
+}
+
+// SearchIndex is an inverted index of the errors and solutions in an ErrorDB
+type SearchIndex struct {
+	errors    []*ErrorDesc
+	solutions map[int]*SolutionDesc
+	// term -> indexes in errors
+	errorTerms map[string][]int
+	// term -> solution IDs
+	solutionTerms map[string][]int
+	// solution ID -> indexes in errors
+	referredBy map[int][]int
+}
+
+// BuildSearchIndex indexes the entries of the database.
+// If the database is not a SolutionIterator, only the solutions referred by the errors are indexed
+func BuildSearchIndex(db ErrorDB) (idx *SearchIndex, err error) {
+	idx = &SearchIndex{
+		solutions:     make(map[int]*SolutionDesc),
+		errorTerms:    make(map[string][]int),
+		solutionTerms: make(map[string][]int),
+		referredBy:    make(map[int][]int),
+	}
+	if err = db.ForEachErrors(func(e *ErrorDesc) error {
+		idx.errors = append(idx.errors, e)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	for i, e := range idx.errors {
+		for _, term := range uniqueTerms(e.Error, e.Message, e.Pattern) {
+			idx.errorTerms[term] = append(idx.errorTerms[term], i)
+		}
+		for _, id := range e.Solutions {
+			idx.referredBy[id] = append(idx.referredBy[id], i)
+		}
+	}
+	if iter, ok := db.(SolutionIterator); ok {
+		if err = iter.ForEachSolutions(func(id int, sol *SolutionDesc) error {
+			idx.solutions[id] = sol
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	} else {
+		for id := range idx.referredBy {
+			sol, err := db.GetSolution(id)
+			if err != nil {
+				if isNotFoundErr(err) {
+					continue
+				}
+				return nil, err
+			}
+			if sol != nil {
+				idx.solutions[id] = sol
+			}
+		}
+	}
+	for id, sol := range idx.solutions {
+		texts := make([]string, 0, 2+len(sol.Tags)+len(sol.Descriptions))
+		texts = append(texts, sol.Description, sol.LinkTo)
+		texts = append(texts, sol.Tags...)
+		for _, desc := range sol.Descriptions {
+			texts = append(texts, desc)
+		}
+		for _, term := range uniqueTerms(texts...) {
+			idx.solutionTerms[term] = append(idx.solutionTerms[term], id)
+		}
+	}
+	return
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// searchTerms splits the text into lower case words,
+// CJK texts are splitted into bigrams since they are not separated by spaces
+func searchTerms(text string) (terms []string) {
+	var (
+		word []rune
+		cjk  []rune
+	)
+	flushCJK := func() {
+		if len(cjk) == 1 {
+			terms = append(terms, (string)(cjk))
+		}
+		for i := 0; i+1 < len(cjk); i++ {
+			terms = append(terms, (string)(cjk[i:i+2]))
+		}
+		cjk = cjk[:0]
+	}
+	flushWord := func() {
+		if len(word) > 0 {
+			terms = append(terms, (string)(word))
+			word = word[:0]
+		}
+	}
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, unicode.ToLower(r))
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return
+}
+
+func uniqueTerms(texts ...string) (terms []string) {
+	for _, text := range texts {
+		terms = append(terms, searchTerms(text)...)
+	}
+	slices.Sort(terms)
+	return slices.Compact(terms)
+}
+
+// score sums the IDF of the terms which present in the postings
+func score[K comparable](terms []string, postings map[string][]K, total int) (scores map[K]float32) {
+	scores = make(map[K]float32)
+	for _, term := range terms {
+		docs := postings[term]
+		if len(docs) == 0 {
+			continue
+		}
+		idf := (float32)(math.Log(1 + (float64)(total)/(float64)(len(docs))))
+		for _, d := range docs {
+			scores[d] += idf
+		}
+	}
+	return
+}
+
+// classMatches checks if the exception class matches the ErrorDesc.Error pattern,
+// a query without package matches any package
+func classMatches(query string, pattern string) bool {
+	qpkg, qcls := rsplit(query, '.')
+	ppkg, pcls := rsplit(pattern, '.')
+	if pcls == "" || pcls == "*" {
+		return true
+	}
+	if !strings.EqualFold(qcls, pcls) {
+		return false
+	}
+	return qpkg == "" || ppkg == "*" || qpkg == ppkg
+}
+
+func (idx *SearchIndex) Search(q SearchQuery) (hits []*SolutionHit) {
+	terms := uniqueTerms(q.Text)
+	errorScores := score(terms, idx.errorTerms, len(idx.errors))
+	solutionScores := score(terms, idx.solutionTerms, len(idx.solutions))
+	for id, sol := range idx.solutions {
+		if !hasAllTags(sol, q.Tags) {
+			continue
+		}
+		hit := &SolutionHit{
+			ID:       id,
+			Score:    solutionScores[id],
+			Solution: sol,
+		}
+		for _, i := range idx.referredBy[id] {
+			e := idx.errors[i]
+			if q.Class != "" && !classMatches(q.Class, e.Error) {
+				continue
+			}
+			if len(terms) > 0 && errorScores[i] == 0 && solutionScores[id] == 0 {
+				continue
+			}
+			hit.Score += errorScores[i]
+			hit.Errors = append(hit.Errors, e)
+		}
+		if q.Class != "" {
+			if len(hit.Errors) == 0 {
+				continue
+			}
+			hit.Score += 1
+		}
+		if len(terms) > 0 && hit.Score == 0 {
+			continue
+		}
+		hits = append(hits, hit)
+	}
+	slices.SortFunc(hits, func(a, b *SolutionHit) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		return a.ID - b.ID
+	})
+	if q.Limit > 0 && len(hits) > q.Limit {
+		hits = hits[:q.Limit]
+	}
+	return
+}
+
+func hasAllTags(sol *SolutionDesc, tags []string) bool {
+	for _, tag := range tags {
+		if !slices.ContainsFunc(sol.Tags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,44 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+)
+
+func TestSearchIndex(t *testing.T) {
+	db := &mapErrorDB{
+		errors: []*ErrorDesc{
+			{ID: 1, Error: "java.lang.OutOfMemoryError", Message: "Java heap space", Solutions: []int{1}},
+			{ID: 2, Error: "java.lang.RuntimeException", Message: "Attempted to load class *", Solutions: []int{2}},
+		},
+		solutions: map[int]*SolutionDesc{
+			1: {Tags: []string{"memory"}, Description: "Allocate more memory", Descriptions: map[string]string{"zh": "分配更多内存"}},
+			2: {Tags: []string{"server"}, Description: "Remove the client-only mod from the server"},
+		},
+	}
+	idx, err := BuildSearchIndex(db)
+	if err != nil {
+		t.Fatalf("BuildSearchIndex: %v", err)
+	}
+	cases := []struct {
+		query  SearchQuery
+		expect []int
+	}{
+		{SearchQuery{Text: "heap"}, []int{1}},
+		{SearchQuery{Text: "内存"}, []int{1}},
+		{SearchQuery{Text: "client mod"}, []int{2}},
+		{SearchQuery{Class: "OutOfMemoryError"}, []int{1}},
+		{SearchQuery{Class: "java.lang.RuntimeException", Text: "memory"}, nil},
+		{SearchQuery{Tags: []string{"server"}}, []int{2}},
+	}
+	for _, c := range cases {
+		hits := idx.Search(c.query)
+		ids := make([]int, len(hits))
+		for i, h := range hits {
+			ids[i] = h.ID
+		}
+		if len(ids) != len(c.expect) || (len(ids) > 0 && ids[0] != c.expect[0]) {
+			t.Errorf("Search(%+v) = %v, expect %v", c.query, ids, c.expect)
+		}
+	}
+}
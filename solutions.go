@@ -0,0 +1,143 @@
+package mcla
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+type ResolvedSolution struct {
+	ID int `json:"id"`
+	// Match is the best match of the errors which refer to the solution
+	Match float32 `json:"match"`
+	// Solution is localized, and its placeholders are expanded
+	Solution *SolutionDesc `json:"solution"`
+}
+
+// UnresolvedSolution is a solution of the matched errors which cannot be fetched from the database
+type UnresolvedSolution struct {
+	ID    int    `json:"id"`
+	Error string `json:"error"`
+}
+
+type cachedSolution struct {
+	sol  *SolutionDesc
+	time time.Time
+}
+
+const (
+	solutionCacheTTL = time.Hour
+	// maxCachedSolutions limits the cached solutions, the expired ones are dropped when it's full
+	maxCachedSolutions = 1024
+)
+
+// GetSolution gets the solution from the database, the result is cached for an hour
+func (a *Analyzer) GetSolution(id int) (sol *SolutionDesc, err error) {
+	a.solMux.RLock()
+	c, ok := a.cachedSolutions[id]
+	a.solMux.RUnlock()
+	if ok && time.Since(c.time) < solutionCacheTTL {
+		return c.sol, nil
+	}
+	if sol, err = a.DB.GetSolution(id); err != nil {
+		return
+	}
+	a.solMux.Lock()
+	defer a.solMux.Unlock()
+	if a.cachedSolutions == nil {
+		a.cachedSolutions = make(map[int]cachedSolution)
+	}
+	if len(a.cachedSolutions) >= maxCachedSolutions {
+		for id, c := range a.cachedSolutions {
+			if time.Since(c.time) >= solutionCacheTTL {
+				delete(a.cachedSolutions, id)
+			}
+		}
+		if len(a.cachedSolutions) >= maxCachedSolutions {
+			clear(a.cachedSolutions)
+		}
+	}
+	a.cachedSolutions[id] = cachedSolution{
+		sol:  sol,
+		time: time.Now(),
+	}
+	return
+}
+
+func (a *Analyzer) hasSolutionTags(sol *SolutionDesc) bool {
+	if len(a.SolutionTags) == 0 {
+		return true
+	}
+	for _, tag := range sol.Tags {
+		if slices.Contains(a.SolutionTags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveResult fills res.Solutions with the solutions of res.Matched.
+// A solution referred by multiple matches appears once, with the best match and its data,
+// and the solutions are sorted by the match in descending order.
+// Solutions that cannot be found in the database are ignored,
+// and the ones failed to fetch, e.g. by a network error, are listed in res.UnresolvedSolutions
+func (a *Analyzer) ResolveResult(res *ErrorResult) {
+	type candidate struct {
+		match float32
+		data  map[string]any
+	}
+	best := make(map[int]candidate)
+	ids := make([]int, 0, 4)
+	for i := range res.Matched {
+		m := &res.Matched[i]
+		if m.Match < a.MinSolutionMatch {
+			continue
+		}
+		for _, id := range m.ErrorDesc.Solutions {
+			c, ok := best[id]
+			if !ok {
+				ids = append(ids, id)
+			} else if c.match >= m.Match {
+				continue
+			}
+			best[id] = candidate{m.Match, m.TemplateData()}
+		}
+	}
+	solutions := make([]*ResolvedSolution, 0, len(ids))
+	res.UnresolvedSolutions = nil
+	for _, id := range ids {
+		sol, err := a.GetSolution(id)
+		if err != nil {
+			if !isNotFoundErr(err) {
+				res.UnresolvedSolutions = append(res.UnresolvedSolutions, UnresolvedSolution{
+					ID:    id,
+					Error: err.Error(),
+				})
+			}
+			continue
+		}
+		if sol == nil || !a.hasSolutionTags(sol) {
+			continue
+		}
+		c := best[id]
+		solutions = append(solutions, &ResolvedSolution{
+			ID:       id,
+			Match:    c.match,
+			Solution: ExpandSolution(sol.Localize(a.Locale), c.data),
+		})
+	}
+	slices.SortStableFunc(solutions, func(x, y *ResolvedSolution) int {
+		switch {
+		case x.Match > y.Match:
+			return -1
+		case x.Match < y.Match:
+			return 1
+		}
+		return 0
+	})
+	res.Solutions = solutions
+}
+
+func isNotFoundErr(err error) bool {
+	return errors.Is(err, ErrEntryNotFound) || errors.Is(err, ErrUnknownDBLayer)
+}
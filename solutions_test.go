@@ -0,0 +1,75 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"context"
+	"errors"
+	"strings"
+)
+
+func TestResolveResult(t *testing.T) {
+	db := &mapErrorDB{
+		solutions: map[int]*SolutionDesc{
+			1: {Tags: []string{"mod"}, Description: "Update ${mod}"},
+			2: {Tags: []string{"java"}, Description: "Update Java"},
+		},
+	}
+	a := NewAnalyzer(db)
+	a.SolutionTags = []string{"mod"}
+	res := &ErrorResult{
+		Matched: []SolutionPossibility{
+			{ErrorDesc: &ErrorDesc{Solutions: []int{1, 2}, Data: map[string]any{"mod": "a"}}, Match: 0.5},
+			{ErrorDesc: &ErrorDesc{Solutions: []int{1, 3}, Data: map[string]any{"mod": "b"}}, Match: 0.9},
+		},
+	}
+	a.ResolveResult(res)
+	if len(res.Solutions) != 1 {
+		t.Fatalf("Expect 1 solution, got %d", len(res.Solutions))
+	}
+	if s := res.Solutions[0]; s.ID != 1 || s.Match != 0.9 || s.Solution.Description != "Update b" {
+		t.Errorf("Unexpected solution %d with match %v: %q", s.ID, s.Match, s.Solution.Description)
+	}
+}
+
+// flakyErrorDB fails to fetch the solutions which are not in the map
+type flakyErrorDB struct {
+	mapErrorDB
+}
+
+func (db *flakyErrorDB) GetSolution(id int) (sol *SolutionDesc, err error) {
+	if sol = db.solutions[id]; sol == nil {
+		return nil, errors.New("connection reset")
+	}
+	return
+}
+
+func TestResolveResultUnresolved(t *testing.T) {
+	db := &flakyErrorDB{mapErrorDB{
+		errors: []*ErrorDesc{
+			{ID: 1, Error: "java.lang.RuntimeException", Message: "Broken *", Solutions: []int{1, 2}},
+		},
+		solutions: map[int]*SolutionDesc{1: {Description: "Fix it"}},
+	}}
+	a := NewAnalyzer(db)
+	a.ResolveSolutions = true
+	result, ctx := a.DoLogStream(context.Background(), strings.NewReader("java.lang.RuntimeException: Broken mod\n\tat a.b.C.d(C.java:1)\n"))
+	var results []*ErrorResult
+	for res := range result {
+		results = append(results, res)
+	}
+	if err := context.Cause(ctx); err != nil && err != context.Canceled {
+		t.Fatalf("Expect the analysis is not aborted, got %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expect 1 result, got %d", len(results))
+	}
+	res := results[0]
+	if len(res.Solutions) != 1 || res.Solutions[0].ID != 1 {
+		t.Errorf("Expect the solution 1 is resolved, got %v", res.Solutions)
+	}
+	if len(res.UnresolvedSolutions) != 1 || res.UnresolvedSolutions[0].ID != 2 || res.UnresolvedSolutions[0].Error != "connection reset" {
+		t.Errorf("Expect the solution 2 is unresolved, got %v", res.UnresolvedSolutions)
+	}
+}
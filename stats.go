@@ -0,0 +1,285 @@
+package mcla
+
+import (
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// crashReportSniffSize is how many bytes are read to find the crash report header
+const crashReportSniffSize = 4096
+
+// isCrashReportFile reports whether the file starts like a crash report,
+// so the other text files like README.txt and options.txt are not analyzed
+func isCrashReportFile(fsys fs.FS, name string) bool {
+	fd, err := fsys.Open(name)
+	if err != nil {
+		// the error is reported when analyzing it
+		return true
+	}
+	defer fd.Close()
+	buf, _ := io.ReadAll(io.LimitReader(decodeReader(fd), crashReportSniffSize))
+	return strings.Contains(strings.ToUpper((string)(buf)), crashReportHeader)
+}
+
+// FindBatchFiles discovers the crash reports and logs in a directory of collected files,
+// which are not required to be in an instance layout.
+// The text files are only analyzed as crash reports when they have the crash report header
+func FindBatchFiles(fsys fs.FS) (files []*InstanceFile, err error) {
+	err = fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		kind, ok := ClassifyLogFile(name)
+		if !ok {
+			switch lower := strings.ToLower(name); {
+			case strings.HasSuffix(lower, ".txt"):
+				if !isCrashReportFile(fsys, name) {
+					return nil
+				}
+				kind = LogKindCrashReport
+			case strings.HasSuffix(lower, ".log.gz"):
+				kind = LogKindArchived
+			case strings.HasSuffix(lower, ".log"):
+				kind = LogKindOther
+			default:
+				return nil
+			}
+		}
+		files = append(files, &InstanceFile{
+			Name: name,
+			Kind: kind,
+			open: func() (io.ReadCloser, error) { return fsys.Open(name) },
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortInstanceFiles(files)
+	return
+}
+
+type CountEntry struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+type FingerprintCount struct {
+	Fingerprint string `json:"fingerprint"`
+	Count       int    `json:"count"`
+	// Files are some of the files which contain the error
+	Files []string `json:"files"`
+}
+
+type SolutionCount struct {
+	ID          int    `json:"id"`
+	Count       int    `json:"count"`
+	Description string `json:"description"`
+}
+
+type Stats struct {
+	Files        int `json:"files"`
+	CrashReports int `json:"crashReports"`
+	JVMCrashes   int `json:"jvmCrashes"`
+	Logs         int `json:"logs"`
+	Failed       int `json:"failed"`
+	// Errors is the number of the errors with their cause chains
+	Errors int `json:"errors"`
+
+	Fingerprints      []*FingerprintCount `json:"fingerprints"`
+	Solutions         []*SolutionCount    `json:"solutions"`
+	SuspectedMods     []CountEntry        `json:"suspectedMods"`
+	MinecraftVersions []CountEntry        `json:"minecraftVersions"`
+	Loaders           []CountEntry        `json:"loaders"`
+	JavaVersions      []CountEntry        `json:"javaVersions"`
+}
+
+const maxFingerprintFiles = 5
+
+// StatsCollector aggregates the results of many files, it's safe for concurrent use
+type StatsCollector struct {
+	mux   sync.Mutex
+	stats Stats
+
+	fingerprints      map[string]*FingerprintCount
+	solutions         map[int]*SolutionCount
+	suspectedMods     map[string]int
+	minecraftVersions map[string]int
+	loaders           map[string]int
+	javaVersions      map[string]int
+}
+
+func NewStatsCollector() *StatsCollector {
+	return &StatsCollector{
+		fingerprints:      make(map[string]*FingerprintCount),
+		solutions:         make(map[int]*SolutionCount),
+		suspectedMods:     make(map[string]int),
+		minecraftVersions: make(map[string]int),
+		loaders:           make(map[string]int),
+		javaVersions:      make(map[string]int),
+	}
+}
+
+// chainMods returns the mods of the suspect frames in the chain, each mod appears once
+func chainMods(jerr *JavaError) (mods []string) {
+	for ; jerr != nil; jerr = jerr.CausedBy {
+		for _, i := range jerr.Stacktrace.SuspectFrames() {
+			mods = addUnique(mods, jerr.Stacktrace[i].ModName())
+		}
+	}
+	return
+}
+
+func (c *StatsCollector) Add(res *InstanceFileResult) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.stats.Files++
+	if res.Failure != "" {
+		c.stats.Failed++
+		return
+	}
+	switch {
+	case res.CrashReport != nil:
+		c.stats.CrashReports++
+		c.addCrashReportDetails(res.CrashReport)
+	case res.JVMCrash != nil:
+		c.stats.JVMCrashes++
+	default:
+		c.stats.Logs++
+	}
+	for _, chain := range GroupChainResults(res.Errors) {
+		c.stats.Errors++
+		root := chain.Root().Error
+		fp := Fingerprint(root)
+		fc, ok := c.fingerprints[fp]
+		if !ok {
+			fc = &FingerprintCount{Fingerprint: fp}
+			c.fingerprints[fp] = fc
+		}
+		fc.Count++
+		if len(fc.Files) < maxFingerprintFiles && !slices.Contains(fc.Files, res.Name) {
+			fc.Files = append(fc.Files, res.Name)
+		}
+		for _, sol := range chain.Solutions() {
+			sc, ok := c.solutions[sol.ID]
+			if !ok {
+				sc = &SolutionCount{ID: sol.ID, Description: sol.Solution.Description}
+				c.solutions[sol.ID] = sc
+			}
+			sc.Count++
+		}
+		for _, mod := range chainMods(root) {
+			c.suspectedMods[mod]++
+		}
+	}
+}
+
+func (c *StatsCollector) addCrashReportDetails(report *CrashReport) {
+	details := report.GetDetails("System Details").Details
+	if v := details.Get("Minecraft Version"); v != "" {
+		c.minecraftVersions[v]++
+	}
+	if v := details.Get("Java Version"); v != "" {
+		v, _ = split(v, ',') // e.g. `17.0.8, Microsoft`
+		c.javaVersions[strings.TrimSpace(v)]++
+	}
+	c.loaders[CrashReportLoader(details)]++
+}
+
+// CrashReportLoader guesses the mod loader and its version by the system details of a crash report
+func CrashReportLoader(details ReportDetails) string {
+	for _, loader := range []string{"NeoForge", "Forge"} {
+		if v := details.Get(loader); v != "" {
+			_, version := rsplit(v, ':') // e.g. `net.minecraftforge:47.1.0`
+			return loader + " " + version
+		}
+	}
+	for _, loader := range []struct{ name, key, id string }{
+		{"Fabric", "Fabric Mods", "fabricloader:"},
+		{"Quilt", "Quilt Mods", "quilt_loader:"},
+	} {
+		if !details.Has(loader.key) {
+			continue
+		}
+		for _, line := range details.GetValues(loader.key) {
+			if rest, ok := strings.CutPrefix(line, loader.id); ok {
+				_, version := rsplit(strings.TrimSpace(rest), ' ') // e.g. `fabricloader: Fabric Loader 0.14.21`
+				return loader.name + " " + version
+			}
+		}
+		return loader.name
+	}
+	modded := details.Get("Is Modded")
+	if _, brand, ok := strings.Cut(modded, "brand changed to '"); ok {
+		brand, _, _ = strings.Cut(brand, "'")
+		return brand
+	}
+	if strings.HasPrefix(modded, "Probably not") {
+		return "Vanilla"
+	}
+	return "Unknown"
+}
+
+func topCounts(counts map[string]int, top int) (entries []CountEntry) {
+	entries = make([]CountEntry, 0, len(counts))
+	for key, n := range counts {
+		entries = append(entries, CountEntry{key, n})
+	}
+	slices.SortFunc(entries, func(a, b CountEntry) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+	if top > 0 && len(entries) > top {
+		entries = entries[:top]
+	}
+	return
+}
+
+// Stats returns the aggregated statistics, each list has at most top entries if top is positive
+func (c *StatsCollector) Stats(top int) *Stats {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	stats := c.stats
+	stats.Fingerprints = make([]*FingerprintCount, 0, len(c.fingerprints))
+	for _, fc := range c.fingerprints {
+		fc := *fc
+		fc.Files = slices.Sorted(slices.Values(fc.Files))
+		stats.Fingerprints = append(stats.Fingerprints, &fc)
+	}
+	slices.SortFunc(stats.Fingerprints, func(a, b *FingerprintCount) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Fingerprint, b.Fingerprint)
+	})
+	stats.Solutions = make([]*SolutionCount, 0, len(c.solutions))
+	for _, sc := range c.solutions {
+		sc := *sc
+		stats.Solutions = append(stats.Solutions, &sc)
+	}
+	slices.SortFunc(stats.Solutions, func(a, b *SolutionCount) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return a.ID - b.ID
+	})
+	if top > 0 {
+		stats.Fingerprints = stats.Fingerprints[:min(top, len(stats.Fingerprints))]
+		stats.Solutions = stats.Solutions[:min(top, len(stats.Solutions))]
+	}
+	stats.SuspectedMods = topCounts(c.suspectedMods, top)
+	stats.MinecraftVersions = topCounts(c.minecraftVersions, top)
+	stats.Loaders = topCounts(c.loaders, top)
+	stats.JavaVersions = topCounts(c.javaVersions, top)
+	return &stats
+}
@@ -0,0 +1,126 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"context"
+	"strings"
+	"testing/fstest"
+)
+
+func TestFingerprint(t *testing.T) {
+	a := &JavaError{
+		Class:   "java.lang.RuntimeException",
+		Message: "Mod 'create' requires 'flywheel' 0.6.10 or above",
+		CausedBy: &JavaError{
+			Class:   "java.lang.NullPointerException",
+			Message: "Cannot invoke \"Object.toString()\" because \"x\" is null",
+			Stacktrace: Stacktrace{
+				{Class: "net.minecraft.world.level.Level", Method: "tick"},
+				{Class: "com.example.mod.Ticker", Method: "run"},
+			},
+		},
+	}
+	b := &JavaError{
+		Class:   "java.lang.RuntimeException",
+		Message: "Mod 'sodium' requires 'indium' 1.0.0 or above",
+		CausedBy: &JavaError{
+			Class:   "java.lang.NullPointerException",
+			Message: "Cannot invoke \"String.length()\" because \"y\" is null",
+			Stacktrace: Stacktrace{
+				{Class: "com.example.mod.Ticker", Method: "run"},
+			},
+		},
+	}
+	expect := "java.lang.RuntimeException: Mod * requires * * or above <- java.lang.NullPointerException: Cannot invoke * because * is null @ com.example.mod.Ticker.run"
+	if got := Fingerprint(a); got != expect {
+		t.Errorf("Fingerprint(a) = %q, expect %q", got, expect)
+	}
+	if fa, fb := Fingerprint(a), Fingerprint(b); fa != fb {
+		t.Errorf("Expect the same fingerprint, got %q and %q", fa, fb)
+	}
+}
+
+func TestCrashReportLoader(t *testing.T) {
+	cases := []struct {
+		details ReportDetails
+		expect  string
+	}{
+		{ReportDetails{"FORGE": {"net.minecraftforge:47.1.0"}}, "Forge 47.1.0"},
+		{ReportDetails{"NEOFORGE": {"20.4.190"}}, "NeoForge 20.4.190"},
+		{ReportDetails{"FABRIC MODS": {"", "fabric-api: Fabric API 0.92.0", "fabricloader: Fabric Loader 0.14.21"}}, "Fabric 0.14.21"},
+		{ReportDetails{"IS MODDED": {"Definitely; Client brand changed to 'fabric'"}}, "fabric"},
+		{ReportDetails{"IS MODDED": {"Probably not. Client jar signature remains and client brand is untouched."}}, "Vanilla"},
+		{ReportDetails{}, "Unknown"},
+	}
+	for _, c := range cases {
+		if got := CrashReportLoader(c.details); got != c.expect {
+			t.Errorf("CrashReportLoader(%v) = %q, expect %q", c.details, got, c.expect)
+		}
+	}
+}
+
+const statsCrashReport = `---- Minecraft Crash Report ----
+Time: 2024-01-01 00:00:00
+Description: Exception in server tick loop
+
+java.lang.RuntimeException: Attempted to load class net/minecraft/client/Minecraft for invalid dist DEDICATED_SERVER
+	at net.minecraftforge.fml.loading.RuntimeDistCleaner.processClassWithFlags(RuntimeDistCleaner.java:57) ~[fmlloader:?]
+
+
+A detailed walkthrough of the error, its code path and all known details is as follows:
+---------------------------------------------------------------------------------------
+
+-- System Details --
+Details:
+	Minecraft Version: 1.20.1
+	Java Version: 17.0.8, Microsoft
+	Forge: net.minecraftforge:47.1.0
+`
+
+func TestStatsCollector(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a/latest.log":    {Data: ([]byte)(instanceLog)},
+		"b/server.log":    {Data: ([]byte)(instanceLog)},
+		"c/crash.txt":     {Data: ([]byte)(statsCrashReport)},
+		"d/notes.md":      {Data: ([]byte)("")},
+		"e/README.txt":    {Data: ([]byte)("not a crash report\n")},
+		"e/options.txt":   {Data: ([]byte)("version:3465\n")},
+		"e/broken.log.gz": {Data: ([]byte)("not gzipped\n")},
+		"f/1.log.gz":      {Data: gzipString("[00:00:00] [main/INFO]: Nothing\n")},
+		"g/modlist.json":  {Data: ([]byte)("{}")},
+	}
+	files, err := FindBatchFiles(fsys)
+	if err != nil {
+		t.Fatalf("FindBatchFiles: %v", err)
+	}
+	if len(files) != 5 {
+		t.Fatalf("Expect 5 files, got %d", len(files))
+	}
+
+	a := NewAnalyzer(&mapErrorDB{})
+	c := NewStatsCollector()
+	for _, f := range files {
+		c.Add(a.AnalyzeFile(context.Background(), f))
+	}
+	stats := c.Stats(10)
+	if stats.Files != 5 || stats.CrashReports != 1 || stats.Logs != 3 || stats.Failed != 1 || stats.Errors != 3 {
+		t.Errorf("Unexpected totals %#v", stats)
+	}
+	if len(stats.Fingerprints) != 1 {
+		t.Fatalf("Expect 1 fingerprint, got %d", len(stats.Fingerprints))
+	}
+	if fp := stats.Fingerprints[0]; fp.Count != 3 || !strings.HasPrefix(fp.Fingerprint, "java.lang.RuntimeException: Attempted to load class *") {
+		t.Errorf("Unexpected fingerprint %#v", fp)
+	}
+	if len(stats.Loaders) != 1 || stats.Loaders[0].Key != "Forge 47.1.0" {
+		t.Errorf("Unexpected loaders %v", stats.Loaders)
+	}
+	if len(stats.JavaVersions) != 1 || stats.JavaVersions[0].Key != "17.0.8" {
+		t.Errorf("Unexpected java versions %v", stats.JavaVersions)
+	}
+	if len(stats.MinecraftVersions) != 1 || stats.MinecraftVersions[0] != (CountEntry{"1.20.1", 1}) {
+		t.Errorf("Unexpected minecraft versions %v", stats.MinecraftVersions)
+	}
+}
@@ -0,0 +1,83 @@
+package mcla
+
+import (
+	"regexp"
+	"strings"
+)
+
+// frameworkPackages are the packages of the JVM, the game, the mod loaders and the common libraries,
+// the frames in them are rarely the cause of a crash
+var frameworkPackages = []string{
+	"java.", "javax.", "jdk.", "sun.", "com.sun.",
+	"net.minecraft.", "com.mojang.", "net.minecraftforge.", "net.neoforged.", "net.fabricmc.", "org.quiltmc.",
+	"cpw.mods.", "org.spongepowered.", "com.llamalad7.mixinextras.",
+	"com.google.", "org.apache.", "io.netty.", "it.unimi.", "org.lwjgl.", "org.objectweb.asm.", "org.slf4j.",
+	"kotlin.", "scala.", "com.electronwill.", "oshi.", "joptsimple.",
+}
+
+// mixinHandlerRe matches the methods injected by mixins, e.g. `handler$zza000$examplemod$onTick`
+var mixinHandlerRe = regexp.MustCompile(`^(?:handler|redirect|modify|wrapOperation|localvar)\$[0-9a-z]+\$([\w]+)\$`)
+
+// jarNameRe matches the jar in the frame suffix, e.g. `~[DistantHorizons-2.0.1-a-1.18.2.jar%2363!/:?]`
+var jarNameRe = regexp.MustCompile(`\[([^\[\]/:]+\.jar)`)
+
+// moduleRe matches the module of the frame in the modern Forge logs, e.g. `TRANSFORMER/examplemod@1.0/`
+var moduleRe = regexp.MustCompile(`\bat\s+[\w-]+/([a-z][\w.-]*)@[^/\s]*/`)
+
+// jarVersionRe matches the version suffix of a jar name, e.g. `-2.0.1-a-1.18.2.jar`
+var jarVersionRe = regexp.MustCompile(`(?i)[-_+](?:v?\d|mc\d|fabric|forge|neoforge).*$|\.jar$`)
+
+// IsFramework reports whether the frame belongs to the JVM, the game, the mod loaders or the common libraries.
+// A frame of a method injected by a mixin is never a framework frame
+func (s StackInfo) IsFramework() bool {
+	if s.MixinOwner() != "" {
+		return false
+	}
+	for _, pkg := range frameworkPackages {
+		if strings.HasPrefix(s.Class, pkg) {
+			return true
+		}
+	}
+	return false
+}
+
+// MixinOwner returns the mod ID of the mixin which injected the method, or an empty string
+func (s StackInfo) MixinOwner() string {
+	if m := mixinHandlerRe.FindStringSubmatch(s.Method); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// JarName returns the file name of the jar which contains the frame, if it's logged
+func (s StackInfo) JarName() string {
+	if m := jarNameRe.FindStringSubmatch(s.Raw); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// SuspectFrames returns the indexes of the frames which are likely to be the cause,
+// they are the frames not belonging to a framework
+func (st Stacktrace) SuspectFrames() (indexes []int) {
+	for i, s := range st {
+		if !s.IsFramework() {
+			indexes = append(indexes, i)
+		}
+	}
+	return
+}
+
+// ModName guesses the mod of the frame by its mixin, module or jar name, or returns an empty string
+func (s StackInfo) ModName() string {
+	if owner := s.MixinOwner(); owner != "" {
+		return owner
+	}
+	if m := moduleRe.FindStringSubmatch(s.Raw); m != nil {
+		return m[1]
+	}
+	if jar := s.JarName(); jar != "" {
+		return jarVersionRe.ReplaceAllString(jar, "")
+	}
+	return ""
+}
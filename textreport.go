@@ -0,0 +1,283 @@
+package mcla
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const defaultMaxSolutions = 3
+
+// ANSI escape codes used by TextRenderer
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiDim    = "\x1b[2m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+)
+
+// TextRenderer formats the analysis results for human,
+// as plain text which can be colored for terminals, or as markdown
+type TextRenderer struct {
+	// Format is either RenderText or RenderMarkdown
+	Format RenderFormat
+	// Color enables ANSI colors for RenderText
+	Color bool
+	// MaxSolutions is the max number of solutions shown for each error, defaults to 3
+	MaxSolutions int
+}
+
+func (r *TextRenderer) markdown() bool {
+	return r.Format == RenderMarkdown
+}
+
+func (r *TextRenderer) style(code string, s string) string {
+	if !r.Color || r.markdown() || s == "" {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+// code quotes s as inline code in markdown
+func (r *TextRenderer) code(s string) string {
+	if !r.markdown() {
+		return s
+	}
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
+
+func (r *TextRenderer) maxSolutions() int {
+	if r.MaxSolutions > 0 {
+		return r.MaxSolutions
+	}
+	return defaultMaxSolutions
+}
+
+// ConfidenceBar draws the match as a bar with width cells
+func ConfidenceBar(match float32, width int) string {
+	filled := min(max((int)(match*(float32)(width)+0.5), 0), width)
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+// errorTitle is the first line of the message, the other lines are returned in rest
+func errorTitle(jerr *JavaError) (title string, rest string) {
+	msg, rest := split(jerr.Message, '\n')
+	if msg == "" {
+		return jerr.Class, rest
+	}
+	return jerr.Class + ": " + msg, rest
+}
+
+func (r *TextRenderer) location(file string, lineNo int) string {
+	if file == "" {
+		return fmt.Sprintf("line %d", lineNo)
+	}
+	return fmt.Sprintf("%s:%d", file, lineNo)
+}
+
+// RenderChain writes an error with its cause chain and the top solutions
+func (r *TextRenderer) RenderChain(w io.Writer, chain ChainResult) (err error) {
+	var sb strings.Builder
+	root := chain.Root()
+	title, rest := errorTitle(root.Error)
+	loc := r.location(root.File, root.Error.LineNo)
+	if r.markdown() {
+		fmt.Fprintf(&sb, "### %s\n\n", r.code(title))
+		fmt.Fprintf(&sb, "At %s\n", r.code(loc))
+		if rest != "" {
+			fmt.Fprintf(&sb, "\n```\n%s\n```\n", rest)
+		}
+	} else {
+		fmt.Fprintf(&sb, "%s %s\n", r.style(ansiDim, loc), r.style(ansiBold+ansiRed, title))
+		for _, line := range strings.Split(rest, "\n") {
+			if line != "" {
+				fmt.Fprintf(&sb, "    %s\n", line)
+			}
+		}
+	}
+	if r.markdown() && root.Error.CausedBy != nil {
+		sb.WriteByte('\n')
+	}
+	for cause := root.Error.CausedBy; cause != nil; cause = cause.CausedBy {
+		title, _ := errorTitle(cause)
+		if r.markdown() {
+			fmt.Fprintf(&sb, "- Caused by %s (line %d)\n", r.code(title), cause.LineNo)
+		} else {
+			fmt.Fprintf(&sb, "  %s %s %s\n", r.style(ansiDim, "↳ caused by"), r.style(ansiRed, title), r.style(ansiDim, fmt.Sprintf("(line %d)", cause.LineNo)))
+		}
+	}
+	r.writeSolutions(&sb, chain.Solutions())
+	sb.WriteByte('\n')
+	_, err = io.WriteString(w, sb.String())
+	return
+}
+
+func (r *TextRenderer) writeSolutions(sb *strings.Builder, solutions []*ResolvedSolution) {
+	if len(solutions) == 0 {
+		if r.markdown() {
+			sb.WriteString("\nNo known solution.\n")
+		} else {
+			fmt.Fprintf(sb, "  %s\n", r.style(ansiDim, "No known solution"))
+		}
+		return
+	}
+	if len(solutions) > r.maxSolutions() {
+		solutions = solutions[:r.maxSolutions()]
+	}
+	if r.markdown() {
+		sb.WriteString("\n**Solutions:**\n\n")
+	} else {
+		fmt.Fprintf(sb, "  %s\n", r.style(ansiBold, "Solutions:"))
+	}
+	for _, sol := range solutions {
+		percent := fmt.Sprintf("%3.0f%%", sol.Match*100)
+		desc := strings.TrimSpace(sol.Solution.Description)
+		// the text may be pasted into markdown, so only the http(s) links are written like RenderSolution
+		link := sol.Solution.LinkTo
+		if !isWebLink(link) {
+			link = ""
+		}
+		if r.markdown() {
+			fmt.Fprintf(sb, "- **%s** %s", strings.TrimSpace(percent), strings.ReplaceAll(desc, "\n", "\n  "))
+			if link != "" {
+				fmt.Fprintf(sb, " <%s>", link)
+			}
+			sb.WriteByte('\n')
+			continue
+		}
+		color := ansiYellow
+		if sol.Match >= 0.8 {
+			color = ansiGreen
+		}
+		fmt.Fprintf(sb, "    %s %s %s\n", r.style(color, ConfidenceBar(sol.Match, 10)), percent, strings.ReplaceAll(desc, "\n", "\n"+strings.Repeat(" ", 20)))
+		if link != "" {
+			fmt.Fprintf(sb, "%s%s\n", strings.Repeat(" ", 20), r.style(ansiCyan, link))
+		}
+	}
+}
+
+// crashReportSummaryKeys are the system details worth to show in the summary
+var crashReportSummaryKeys = []string{"Minecraft Version", "Operating System", "Java Version", "Is Modded", "Type"}
+
+// RenderCrashReport writes the description, the error chain and the key system details of the report
+func (r *TextRenderer) RenderCrashReport(w io.Writer, report *CrashReport) (err error) {
+	var sb strings.Builder
+	if r.markdown() {
+		fmt.Fprintf(&sb, "## Crash report: %s\n\n", markdownEscaper.Replace(report.Description))
+	} else {
+		fmt.Fprintf(&sb, "%s %s\n", r.style(ansiBold, "Crash report:"), r.style(ansiBold+ansiRed, report.Description))
+	}
+	if report.Error != nil {
+		for jerr, prefix := report.Error, ""; jerr != nil; jerr, prefix = jerr.CausedBy, "caused by " {
+			title, _ := errorTitle(jerr)
+			if r.markdown() {
+				fmt.Fprintf(&sb, "- %s%s\n", prefix, r.code(title))
+			} else {
+				fmt.Fprintf(&sb, "  %s%s\n", r.style(ansiDim, prefix), r.style(ansiRed, title))
+			}
+		}
+	}
+	if thread := report.HeadThread.Thread; thread != "" {
+		if r.markdown() {
+			fmt.Fprintf(&sb, "\nThread: %s\n", r.code(thread))
+		} else {
+			fmt.Fprintf(&sb, "  %s %s\n", r.style(ansiDim, "Thread:"), thread)
+		}
+	}
+	details := report.GetDetails("System Details").Details
+	first := true
+	for _, key := range crashReportSummaryKeys {
+		value := details.Get(key)
+		if value == "" {
+			continue
+		}
+		if r.markdown() {
+			if first {
+				sb.WriteString("\n| Detail | Value |\n| --- | --- |\n")
+			}
+			fmt.Fprintf(&sb, "| %s | %s |\n", key, markdownEscaper.Replace(strings.ReplaceAll(value, "\n", " ")))
+		} else {
+			fmt.Fprintf(&sb, "  %s %s\n", r.style(ansiDim, key+":"), strings.ReplaceAll(value, "\n", "; "))
+		}
+		first = false
+	}
+	sb.WriteByte('\n')
+	_, err = io.WriteString(w, sb.String())
+	return
+}
+
+// RenderJVMCrash writes the summary of a hs_err_pid log
+func (r *TextRenderer) RenderJVMCrash(w io.Writer, log *JVMCrashLog) (err error) {
+	var sb strings.Builder
+	if r.markdown() {
+		fmt.Fprintf(&sb, "## JVM crash\n\n```\n%s\n```\n", log.Description)
+		if log.ProblematicFrame != "" {
+			fmt.Fprintf(&sb, "\nProblematic frame: %s\n", r.code(log.ProblematicFrame))
+		}
+		if log.JREVersion != "" {
+			fmt.Fprintf(&sb, "\nJRE version: %s\n", r.code(log.JREVersion))
+		}
+	} else {
+		fmt.Fprintf(&sb, "%s %s\n", r.style(ansiBold, "JVM crash:"), r.style(ansiBold+ansiRed, strings.ReplaceAll(log.Description, "\n", "\n    ")))
+		if log.ProblematicFrame != "" {
+			fmt.Fprintf(&sb, "  %s %s\n", r.style(ansiDim, "Problematic frame:"), log.ProblematicFrame)
+		}
+		if log.JREVersion != "" {
+			fmt.Fprintf(&sb, "  %s %s\n", r.style(ansiDim, "JRE version:"), log.JREVersion)
+		}
+	}
+	sb.WriteByte('\n')
+	_, err = io.WriteString(w, sb.String())
+	return
+}
+
+// RenderInstanceReport writes the results of every file in the report
+func (r *TextRenderer) RenderInstanceReport(w io.Writer, report *InstanceReport) (err error) {
+	for _, file := range report.Files {
+		var header string
+		if r.markdown() {
+			header = fmt.Sprintf("# %s\n\n", r.code(file.Name))
+		} else {
+			header = fmt.Sprintf("%s\n\n", r.style(ansiBold+ansiCyan, "== "+file.Name+" =="))
+		}
+		if _, err = io.WriteString(w, header); err != nil {
+			return
+		}
+		if file.Failure != "" {
+			if _, err = fmt.Fprintf(w, "Failed to analyze: %s\n\n", file.Failure); err != nil {
+				return
+			}
+		}
+		if file.CrashReport != nil {
+			if err = r.RenderCrashReport(w, file.CrashReport); err != nil {
+				return
+			}
+		}
+		if file.JVMCrash != nil {
+			if err = r.RenderJVMCrash(w, file.JVMCrash); err != nil {
+				return
+			}
+		}
+		for _, chain := range GroupChainResults(file.Errors) {
+			if err = r.RenderChain(w, chain); err != nil {
+				return
+			}
+		}
+		if file.Duplicates > 0 {
+			if _, err = fmt.Fprintf(w, "%d error(s) already shown above were omitted\n\n", file.Duplicates); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,94 @@
+package mcla_test
+
+import (
+	. "github.com/GlobeMC/mcla"
+	"testing"
+
+	"strings"
+)
+
+func TestConfidenceBar(t *testing.T) {
+	cases := []struct {
+		match  float32
+		expect string
+	}{
+		{0, "░░░░░"},
+		{0.5, "███░░"},
+		{1, "█████"},
+		{1.2, "█████"},
+	}
+	for _, c := range cases {
+		if got := ConfidenceBar(c.match, 5); got != c.expect {
+			t.Errorf("ConfidenceBar(%v, 5) = %q, expect %q", c.match, got, c.expect)
+		}
+	}
+}
+
+func TestRenderChain(t *testing.T) {
+	cause := &JavaError{Class: "java.lang.IllegalStateException", Message: "Not ready", LineNo: 5}
+	root := &JavaError{Class: "java.lang.RuntimeException", Message: "Failed", LineNo: 2, CausedBy: cause}
+	results := []*ErrorResult{
+		{
+			Error: cause,
+			Solutions: []*ResolvedSolution{
+				{ID: 1, Match: 0.5, Solution: &SolutionDesc{Description: "Restart"}},
+				{ID: 2, Match: 0.9, Solution: &SolutionDesc{Description: "Update the mod", LinkTo: "https://example.com"}},
+			},
+		},
+		{
+			Error: root,
+			File:  "latest.log",
+			Solutions: []*ResolvedSolution{
+				{ID: 1, Match: 0.7, Solution: &SolutionDesc{Description: "Restart"}},
+			},
+		},
+	}
+	chains := GroupChainResults(results)
+	if len(chains) != 1 || len(chains[0]) != 2 || !chains[0].Complete() {
+		t.Fatalf("Expect 1 complete chain, got %d", len(chains))
+	}
+	sols := chains[0].Solutions()
+	if len(sols) != 2 || sols[0].ID != 2 || sols[1].Match != 0.7 {
+		t.Errorf("Unexpected merged solutions %v", sols)
+	}
+
+	var sb strings.Builder
+	r := &TextRenderer{Format: RenderText}
+	if err := r.RenderChain(&sb, chains[0]); err != nil {
+		t.Fatalf("RenderChain: %v", err)
+	}
+	out := sb.String()
+	for _, expect := range []string{
+		"latest.log:2 java.lang.RuntimeException: Failed\n",
+		"caused by java.lang.IllegalStateException: Not ready (line 5)",
+		"█████████░  90% Update the mod\n",
+		"https://example.com",
+		"███████░░░  70% Restart",
+	} {
+		if !strings.Contains(out, expect) {
+			t.Errorf("Expect %q in the output:\n%s", expect, out)
+		}
+	}
+	if strings.Contains(out, "\x1b[") {
+		t.Errorf("Unexpected ANSI codes in the output:\n%s", out)
+	}
+}
+
+func TestRenderChainUnsafeLink(t *testing.T) {
+	results := []*ErrorResult{{
+		Error: &JavaError{Class: "java.lang.RuntimeException", Message: "Failed", LineNo: 2},
+		Solutions: []*ResolvedSolution{
+			{ID: 1, Match: 0.9, Solution: &SolutionDesc{Description: "Click it", LinkTo: "javascript:alert(1)"}},
+		},
+	}}
+	for _, format := range []RenderFormat{RenderText, RenderMarkdown} {
+		var sb strings.Builder
+		r := &TextRenderer{Format: format}
+		if err := r.RenderChain(&sb, GroupChainResults(results)[0]); err != nil {
+			t.Fatalf("RenderChain: %v", err)
+		}
+		if out := sb.String(); strings.Contains(out, "javascript:") || !strings.Contains(out, "Click it") {
+			t.Errorf("Expect the link is omitted in %s:\n%s", format, out)
+		}
+	}
+}